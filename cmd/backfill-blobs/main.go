@@ -0,0 +1,123 @@
+package main
+
+import (
+	"art-collection-system/internal/config"
+	"art-collection-system/internal/database"
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/repository"
+	"art-collection-system/internal/service"
+	"art-collection-system/internal/storage"
+	"art-collection-system/internal/utils"
+	"flag"
+	"fmt"
+	"log"
+)
+
+// backfill-blobs moves the files of artworks and revisions uploaded before
+// content-addressed storage into blobs, so identical files are stored once.
+// Each legacy file is copied into its blob, the record is pointed at the blob
+// and the legacy file and its renditions are deleted.
+//
+// Usage: go run ./cmd/backfill-blobs [-config config/config.yaml] [-batch 100]
+
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "path to the configuration file")
+	batchSize := flag.Int("batch", 100, "number of artworks loaded per batch")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.InitMySQL(database.MySQLConfig{
+		Host:         cfg.Database.MySQL.Host,
+		Port:         cfg.Database.MySQL.Port,
+		User:         cfg.Database.MySQL.User,
+		Password:     cfg.Database.MySQL.Password,
+		DBName:       cfg.Database.MySQL.DBName,
+		MaxIdleConns: cfg.Database.MySQL.MaxIdleConns,
+		MaxOpenConns: cfg.Database.MySQL.MaxOpenConns,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to MySQL: %v", err)
+	}
+
+	store, err := storage.New(cfg.GetStorageConfig(""))
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	artworkRepo := repository.NewArtworkRepository(db)
+	utils.InitImageDecodeLimit(cfg.Upload.MaxImagePixels)
+	fileService := service.NewFileService(store, repository.NewBlobRepository(db), cfg.Upload.KeepOriginalMetadata)
+
+	var moved, failed int
+	// move imports one legacy file and points its record at the blob with update
+	move := func(label, legacyPath string, update func(blob *models.Blob) (bool, error)) {
+		blob, err := fileService.ImportFile(legacyPath)
+		if err != nil {
+			failed++
+			log.Printf("%s (%s): %v", label, legacyPath, err)
+			return
+		}
+
+		updated, err := update(blob)
+		if err != nil || !updated {
+			// The record changed meanwhile; give the reference back
+			_ = fileService.ReleaseFile(&blob.ID, blob.Path)
+			if err != nil {
+				failed++
+				log.Printf("%s (%s): %v", label, legacyPath, err)
+			}
+			return
+		}
+
+		if err := fileService.DeleteFile(legacyPath); err != nil {
+			log.Printf("%s (%s): moved, but the legacy file was not deleted: %v", label, legacyPath, err)
+		}
+		moved++
+	}
+
+	var lastID uint
+	for {
+		artworks, err := artworkRepo.GetBatchAfterID(lastID, *batchSize)
+		if err != nil {
+			log.Fatalf("Failed to load artworks: %v", err)
+		}
+		if len(artworks) == 0 {
+			break
+		}
+
+		ids := make([]uint, 0, len(artworks))
+		for _, artwork := range artworks {
+			lastID = artwork.ID
+			ids = append(ids, artwork.ID)
+			if artwork.BlobID != nil {
+				continue
+			}
+
+			artworkID, legacyPath := artwork.ID, artwork.FilePath
+			move(fmt.Sprintf("artwork %d", artworkID), legacyPath, func(blob *models.Blob) (bool, error) {
+				return artworkRepo.MoveToBlob(artworkID, legacyPath, blob)
+			})
+		}
+
+		revisions, err := artworkRepo.GetRevisionsByArtworkIDs(ids)
+		if err != nil {
+			log.Fatalf("Failed to load artwork revisions: %v", err)
+		}
+		for _, revision := range revisions {
+			if revision.BlobID != nil {
+				continue
+			}
+
+			revisionID, legacyPath := revision.ID, revision.FilePath
+			move(fmt.Sprintf("revision %d", revisionID), legacyPath, func(blob *models.Blob) (bool, error) {
+				return artworkRepo.MoveRevisionToBlob(revisionID, legacyPath, blob)
+			})
+		}
+	}
+
+	log.Printf("Files moved into blobs: %d, failed: %d", moved, failed)
+}
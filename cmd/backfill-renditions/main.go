@@ -0,0 +1,74 @@
+package main
+
+import (
+	"art-collection-system/internal/config"
+	"art-collection-system/internal/database"
+	"art-collection-system/internal/repository"
+	"art-collection-system/internal/service"
+	"art-collection-system/internal/storage"
+	"art-collection-system/internal/utils"
+	"flag"
+	"log"
+)
+
+// backfill-renditions generates the thumbnail and preview renditions of artworks
+// uploaded before renditions were introduced, or regenerates them after a change
+// of rendition sizes.
+//
+// Usage: go run ./cmd/backfill-renditions [-config config/config.yaml] [-batch 100]
+
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "path to the configuration file")
+	batchSize := flag.Int("batch", 100, "number of artworks loaded per batch")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.InitMySQL(database.MySQLConfig{
+		Host:         cfg.Database.MySQL.Host,
+		Port:         cfg.Database.MySQL.Port,
+		User:         cfg.Database.MySQL.User,
+		Password:     cfg.Database.MySQL.Password,
+		DBName:       cfg.Database.MySQL.DBName,
+		MaxIdleConns: cfg.Database.MySQL.MaxIdleConns,
+		MaxOpenConns: cfg.Database.MySQL.MaxOpenConns,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to MySQL: %v", err)
+	}
+
+	artworkRepo := repository.NewArtworkRepository(db)
+	store, err := storage.New(cfg.GetStorageConfig(""))
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	utils.InitImageDecodeLimit(cfg.Upload.MaxImagePixels)
+	fileService := service.NewFileService(store, repository.NewBlobRepository(db), cfg.Upload.KeepOriginalMetadata)
+
+	var processed, failed int
+	var lastID uint
+	for {
+		artworks, err := artworkRepo.GetBatchAfterID(lastID, *batchSize)
+		if err != nil {
+			log.Fatalf("Failed to load artworks: %v", err)
+		}
+		if len(artworks) == 0 {
+			break
+		}
+
+		for _, artwork := range artworks {
+			lastID = artwork.ID
+			if err := fileService.GenerateRenditions(artwork.FilePath); err != nil {
+				failed++
+				log.Printf("artwork %d (%s): %v", artwork.ID, artwork.FilePath, err)
+				continue
+			}
+			processed++
+		}
+	}
+
+	log.Printf("Renditions generated for %d artworks, %d failed", processed, failed)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"art-collection-system/internal/config"
+	"art-collection-system/internal/database"
+	"art-collection-system/internal/repository"
+	"art-collection-system/internal/service"
+	"art-collection-system/internal/storage"
+	"context"
+	"flag"
+	"log"
+	"time"
+)
+
+// check-storage compares the configured storage with the database. It lists
+// stored files no artwork, page, revision or blob references, blobs no artwork,
+// page or revision points at, records whose file is missing and blobs whose reference
+// count is wrong, and reports how many bytes the orphans take up.
+//
+// By default nothing is changed. With -action quarantine, orphans older than
+// the grace period are moved under quarantine/ in the same storage; with
+// -action delete they are removed. Missing files and reference count mismatches
+// are only reported.
+//
+// Usage: go run ./cmd/check-storage [-config config/config.yaml] [-grace 24h] [-action report|quarantine|delete] [-v]
+
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "path to the configuration file")
+	grace := flag.Duration("grace", 24*time.Hour, "only clean up orphans older than this")
+	action := flag.String("action", service.StorageActionReport, "report, quarantine or delete")
+	verbose := flag.Bool("v", false, "list every orphaned file")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.InitMySQL(database.MySQLConfig{
+		Host:         cfg.Database.MySQL.Host,
+		Port:         cfg.Database.MySQL.Port,
+		User:         cfg.Database.MySQL.User,
+		Password:     cfg.Database.MySQL.Password,
+		DBName:       cfg.Database.MySQL.DBName,
+		MaxIdleConns: cfg.Database.MySQL.MaxIdleConns,
+		MaxOpenConns: cfg.Database.MySQL.MaxOpenConns,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to MySQL: %v", err)
+	}
+
+	store, err := storage.New(cfg.GetStorageConfig(""))
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	checkService := service.NewStorageCheckService(store, repository.NewArtworkRepository(db), repository.NewBlobRepository(db))
+	report, err := checkService.Check(context.Background(), *grace, *action)
+	if err != nil {
+		log.Fatalf("Storage check failed: %v", err)
+	}
+
+	for _, orphan := range report.OrphanedFiles {
+		if *verbose || orphan.Eligible {
+			log.Printf("orphaned file %s (%d bytes, modified %s, eligible: %t)", orphan.Key, orphan.Size, orphan.ModTime.Format(time.RFC3339), orphan.Eligible)
+		}
+	}
+	for _, blob := range report.UnreferencedBlobs {
+		log.Printf("unreferenced blob %d %s (%d bytes, eligible: %t)", blob.ID, blob.Path, blob.Size, blob.Eligible)
+	}
+	for _, missing := range report.MissingFiles {
+		log.Printf("missing file: %s %d (artwork %d): %s", missing.Kind, missing.ID, missing.ArtworkID, missing.Path)
+	}
+	for _, mismatch := range report.BlobRefMismatches {
+		log.Printf("blob %d: ref_count %d, actual references %d", mismatch.ID, mismatch.RefCount, mismatch.References)
+	}
+
+	log.Printf("Objects: %d (%d bytes), orphaned files: %d, unreferenced blobs: %d, reclaimable: %d bytes",
+		report.ObjectCount, report.TotalBytes, len(report.OrphanedFiles), len(report.UnreferencedBlobs), report.ReclaimableBytes)
+	log.Printf("Missing files: %d, blob reference count mismatches: %d", len(report.MissingFiles), len(report.BlobRefMismatches))
+	if *action != service.StorageActionReport {
+		log.Printf("Orphans processed (%s): %d, failed: %d", *action, report.Processed, report.Failed)
+		if report.Failed > 0 {
+			log.Fatalf("Some orphans could not be cleaned up; run the command again")
+		}
+	}
+}
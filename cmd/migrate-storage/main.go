@@ -0,0 +1,200 @@
+package main
+
+import (
+	"art-collection-system/internal/config"
+	"art-collection-system/internal/database"
+	"art-collection-system/internal/repository"
+	"art-collection-system/internal/service"
+	"art-collection-system/internal/storage"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+)
+
+// migrate-storage copies the files of all artworks and their previous revisions,
+// including renditions, from one storage backend to another. Every copied object
+// is read back from the destination and its SHA-256 compared with the source.
+// Objects already present in the destination with the same checksum are skipped,
+// so an interrupted migration can simply be run again.
+//
+// Both backends are built from the same configuration file: "local" uses
+// upload.path and "s3" uses the storage.s3 section.
+//
+// Usage: go run ./cmd/migrate-storage -from local -to s3 [-config config/config.yaml] [-batch 100] [-dry-run]
+
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "path to the configuration file")
+	from := flag.String("from", "", "source storage driver (local or s3)")
+	to := flag.String("to", "", "destination storage driver (local or s3)")
+	batchSize := flag.Int("batch", 100, "number of artworks loaded per batch")
+	dryRun := flag.Bool("dry-run", false, "only report the objects that would be copied")
+	flag.Parse()
+
+	if *from == "" || *to == "" || *from == *to {
+		log.Fatalf("-from and -to must name two different storage drivers")
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.InitMySQL(database.MySQLConfig{
+		Host:         cfg.Database.MySQL.Host,
+		Port:         cfg.Database.MySQL.Port,
+		User:         cfg.Database.MySQL.User,
+		Password:     cfg.Database.MySQL.Password,
+		DBName:       cfg.Database.MySQL.DBName,
+		MaxIdleConns: cfg.Database.MySQL.MaxIdleConns,
+		MaxOpenConns: cfg.Database.MySQL.MaxOpenConns,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to MySQL: %v", err)
+	}
+
+	src, err := storage.New(cfg.GetStorageConfig(*from))
+	if err != nil {
+		log.Fatalf("Failed to initialize source storage: %v", err)
+	}
+	dst, err := storage.New(cfg.GetStorageConfig(*to))
+	if err != nil {
+		log.Fatalf("Failed to initialize destination storage: %v", err)
+	}
+
+	artworkRepo := repository.NewArtworkRepository(db)
+	ctx := context.Background()
+
+	var copied, skipped, missing, failed int
+	migrate := func(key string, required bool) {
+		result, err := migrateObject(ctx, src, dst, key, *dryRun)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			// Renditions are optional; a missing original is reported
+			if required {
+				missing++
+				log.Printf("%s: missing in source", key)
+			}
+		case err != nil:
+			failed++
+			log.Printf("%s: %v", key, err)
+		case result == resultSkipped:
+			skipped++
+		default:
+			copied++
+		}
+	}
+	migrateWithRenditions := func(key string) {
+		migrate(key, true)
+		for _, renditionPath := range service.RenditionPaths(key) {
+			migrate(renditionPath, false)
+		}
+	}
+
+	var lastID uint
+	for {
+		artworks, err := artworkRepo.GetBatchAfterID(lastID, *batchSize)
+		if err != nil {
+			log.Fatalf("Failed to load artworks: %v", err)
+		}
+		if len(artworks) == 0 {
+			break
+		}
+
+		ids := make([]uint, 0, len(artworks))
+		for _, artwork := range artworks {
+			lastID = artwork.ID
+			ids = append(ids, artwork.ID)
+			migrateWithRenditions(artwork.FilePath)
+		}
+
+		revisions, err := artworkRepo.GetRevisionsByArtworkIDs(ids)
+		if err != nil {
+			log.Fatalf("Failed to load artwork revisions: %v", err)
+		}
+		for _, revision := range revisions {
+			migrateWithRenditions(revision.FilePath)
+		}
+	}
+
+	verb := "copied"
+	if *dryRun {
+		verb = "to copy"
+	}
+	log.Printf("Objects %s: %d, already present: %d, missing in source: %d, failed: %d", verb, copied, skipped, missing, failed)
+	if failed > 0 || missing > 0 {
+		log.Fatalf("Migration incomplete; fix the errors above and run it again")
+	}
+}
+
+// migrateResult is the outcome of migrating one object
+type migrateResult int
+
+const (
+	resultCopied migrateResult = iota
+	resultSkipped
+)
+
+// migrateObject copies one object and verifies the copy by checksum
+func migrateObject(ctx context.Context, src, dst storage.Storage, key string, dryRun bool) (migrateResult, error) {
+	reader, info, err := src.Get(ctx, key)
+	if err != nil {
+		return resultCopied, err
+	}
+	defer reader.Close()
+
+	srcHash, err := hashReader(reader)
+	if err != nil {
+		return resultCopied, fmt.Errorf("failed to read source: %w", err)
+	}
+
+	// Skip objects that are already in place, e.g. from an earlier interrupted run
+	if existing, err := dst.Stat(ctx, key); err == nil && existing.Size == info.Size {
+		if dstHash, err := hashObject(ctx, dst, key); err == nil && dstHash == srcHash {
+			return resultSkipped, nil
+		}
+	}
+
+	if dryRun {
+		return resultCopied, nil
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return resultCopied, fmt.Errorf("failed to read source: %w", err)
+	}
+	if err := dst.Put(ctx, key, reader, info.Size, storage.PutOptions{SHA256: srcHash}); err != nil {
+		return resultCopied, err
+	}
+
+	dstHash, err := hashObject(ctx, dst, key)
+	if err != nil {
+		return resultCopied, fmt.Errorf("failed to verify copy: %w", err)
+	}
+	if dstHash != srcHash {
+		return resultCopied, fmt.Errorf("checksum mismatch after copy: source %s, destination %s", srcHash, dstHash)
+	}
+	return resultCopied, nil
+}
+
+// hashObject computes the SHA-256 of a stored object by reading it back
+func hashObject(ctx context.Context, store storage.Storage, key string) (string, error) {
+	reader, _, err := store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	return hashReader(reader)
+}
+
+// hashReader computes the hex SHA-256 of everything read from r
+func hashReader(r io.Reader) (string, error) {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
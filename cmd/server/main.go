@@ -8,10 +8,13 @@ import (
 	"art-collection-system/internal/repository"
 	"art-collection-system/internal/routes"
 	"art-collection-system/internal/service"
+	"art-collection-system/internal/storage"
 	"art-collection-system/internal/utils"
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -64,6 +67,19 @@ func main() {
 	utils.InitJWT(cfg.JWT.Secret)
 	logger.Info("JWT initialized")
 
+	// Initialize image URL signing
+	utils.InitImageURLSigner(cfg.ImageURL.Secret)
+
+	// Initialize the image decoding limit
+	utils.InitImageDecodeLimit(cfg.Upload.MaxImagePixels)
+
+	// Initialize file storage
+	store, err := storage.New(cfg.GetStorageConfig(""))
+	if err != nil {
+		logger.Fatal("Failed to initialize storage", zap.Error(err))
+	}
+	logger.Info("Storage initialized", zap.String("driver", cfg.Storage.Driver))
+
 	// Initialize email service
 	emailService := utils.NewEmailService(&cfg.Email)
 	logger.Info("Email service initialized")
@@ -72,21 +88,42 @@ func main() {
 	userRepo := repository.NewUserRepository(db)
 	activityRepo := repository.NewActivityRepository(db)
 	artworkRepo := repository.NewArtworkRepository(db)
+	artworkReviewRepo := repository.NewArtworkReviewRepository(db)
+	scoringRepo := repository.NewScoringRepository(db)
+	blobRepo := repository.NewBlobRepository(db)
+	voteRepo := repository.NewVoteRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
 
 	// Initialize services
 	authService := service.NewAuthService(userRepo, redisClient, emailService)
 	userService := service.NewUserService(userRepo, artworkRepo)
 	activityService := service.NewActivityService(activityRepo)
-	fileService := service.NewFileService(cfg.Upload.Path)
-	artworkService := service.NewArtworkService(artworkRepo, activityService, fileService)
+	fileService := service.NewFileService(store, blobRepo, cfg.Upload.KeepOriginalMetadata)
+	voteService := service.NewVoteService(redisClient, voteRepo, artworkRepo, activityService)
+	artworkService := service.NewArtworkService(artworkRepo, artworkReviewRepo, activityService, fileService, voteService, cfg.Upload.DuplicatePolicy, cfg.GetTrashRetention())
 	adminService := service.NewAdminService(userRepo)
+	reviewLeaseService := service.NewReviewLeaseService(redisClient, artworkRepo, cfg.GetReviewLeaseDuration(), cfg.Review.MaxClaimSize)
+	scoringService := service.NewScoringService(scoringRepo, artworkRepo, userRepo, activityService)
+	galleryService := service.NewGalleryService(artworkRepo, activityService, voteService)
+	commentService := service.NewCommentService(commentRepo, artworkRepo, userRepo, activityService, emailService)
+	imageURLService := service.NewImageURLService(cfg.GetImageURLTTL())
+	storageCheckService := service.NewStorageCheckService(store, artworkRepo, blobRepo)
+	resumableUploadService := service.NewResumableUploadService(redisClient, store, artworkService, cfg.Upload.MaxSize, cfg.GetResumableUploadTTL())
+	exportService := service.NewExportService(artworkRepo, userRepo, activityService, fileService)
+	jobRunner := service.NewJobRunner(redisClient)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService)
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, imageURLService)
 	activityHandler := handler.NewActivityHandler(activityService)
-	artworkHandler := handler.NewArtworkHandler(artworkService, fileService)
-	adminHandler := handler.NewAdminHandler(artworkService, adminService)
+	artworkHandler := handler.NewArtworkHandler(artworkService, fileService, imageURLService, cfg.Upload.MaxSize)
+	adminHandler := handler.NewAdminHandler(artworkService, adminService, reviewLeaseService, imageURLService, storageCheckService)
+	scoringHandler := handler.NewScoringHandler(scoringService, imageURLService)
+	galleryHandler := handler.NewGalleryHandler(galleryService, fileService)
+	uploadHandler := handler.NewUploadHandler(resumableUploadService, imageURLService)
+	voteHandler := handler.NewVoteHandler(voteService)
+	commentHandler := handler.NewCommentHandler(commentService)
+	exportHandler := handler.NewExportHandler(exportService, reviewLeaseService, cfg.Export.HeaderLanguage)
 
 	// Initialize middlewares
 	authMiddleware := middleware.AuthMiddleware(authService)
@@ -109,15 +146,76 @@ func main() {
 		activityHandler,
 		artworkHandler,
 		adminHandler,
+		scoringHandler,
+		galleryHandler,
+		exportHandler,
+		uploadHandler,
+		voteHandler,
+		commentHandler,
 		authMiddleware,
 		adminMiddleware,
 		redisClient,
 	)
 
-	// Create uploads directory if it doesn't exist
-	if err := os.MkdirAll(cfg.Upload.Path, 0755); err != nil {
-		logger.Fatal("Failed to create uploads directory", zap.Error(err))
-	}
+	// Periodically remove the data of abandoned resumable uploads, the drafts that can no
+	// longer be submitted because their activity has ended, and artworks long in the trash
+	// Each job runs on one instance at a time when several are deployed
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			err := jobRunner.RunExclusive("resumable-upload-cleanup", time.Hour, func() error {
+				count, err := resumableUploadService.CleanupExpired(context.Background())
+				if err == nil && count > 0 {
+					logger.Info("Expired resumable uploads cleaned up", zap.Int("count", count))
+				}
+				return err
+			})
+			if err != nil {
+				logger.Error("Failed to clean up expired resumable uploads", zap.Error(err))
+			}
+
+			err = jobRunner.RunExclusive("expired-draft-cleanup", time.Hour, func() error {
+				discarded, err := artworkService.DiscardExpiredDrafts()
+				if err == nil && discarded > 0 {
+					logger.Info("Expired drafts discarded", zap.Int("count", discarded))
+				}
+				return err
+			})
+			if err != nil {
+				logger.Error("Failed to discard expired drafts", zap.Error(err))
+			}
+
+			err = jobRunner.RunExclusive("trash-purge", time.Hour, func() error {
+				purged, err := artworkService.PurgeDeletedArtworks()
+				if err == nil && purged > 0 {
+					logger.Info("Deleted artworks purged", zap.Int("count", purged))
+				}
+				return err
+			})
+			if err != nil {
+				logger.Error("Failed to purge deleted artworks", zap.Error(err))
+			}
+		}
+	}()
+
+	// Periodically store the live vote counts kept in Redis in the database, on one instance at a time
+	go func() {
+		ticker := time.NewTicker(cfg.GetVoteSyncInterval())
+		defer ticker.Stop()
+		for range ticker.C {
+			err := jobRunner.RunExclusive("vote-sync", cfg.GetVoteSyncInterval(), func() error {
+				synced, err := voteService.SyncVoteCounts(context.Background())
+				if err == nil && synced > 0 {
+					logger.Info("Vote counts synced", zap.Int("activities", synced))
+				}
+				return err
+			})
+			if err != nil {
+				logger.Error("Failed to sync vote counts", zap.Error(err))
+			}
+		}
+	}()
 
 	// Start HTTP server
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"art-collection-system/internal/storage"
 	"fmt"
 	"time"
 
@@ -12,7 +13,12 @@ type Config struct {
 	Server   ServerConfig   `mapstructure:"server"`
 	Database DatabaseConfig `mapstructure:"database"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
+	ImageURL ImageURLConfig `mapstructure:"image_url"`
 	Upload   UploadConfig   `mapstructure:"upload"`
+	Storage  StorageConfig  `mapstructure:"storage"`
+	Review   ReviewConfig   `mapstructure:"review"`
+	Export   ExportConfig   `mapstructure:"export"`
+	Voting   VotingConfig   `mapstructure:"voting"`
 	Email    EmailConfig    `mapstructure:"email"`
 	Log      LogConfig      `mapstructure:"log"`
 }
@@ -54,10 +60,53 @@ type JWTConfig struct {
 	ExpireHours int    `mapstructure:"expire_hours"`
 }
 
+// ImageURLConfig 签名图片链接配置
+type ImageURLConfig struct {
+	Secret     string `mapstructure:"secret"`      // 图片链接签名密钥，应与 JWT 密钥不同
+	TTLMinutes int    `mapstructure:"ttl_minutes"` // 签名链接的最短有效期（分钟），实际有效期在 1 到 2 倍之间
+}
+
 // UploadConfig 文件上传配置
 type UploadConfig struct {
-	Path    string `mapstructure:"path"`
-	MaxSize int64  `mapstructure:"max_size"` // 字节
+	Path                 string `mapstructure:"path"`
+	MaxSize              int64  `mapstructure:"max_size"`               // 字节
+	KeepOriginalMetadata bool   `mapstructure:"keep_original_metadata"` // 是否保留被去除的原始图片元数据（仅管理员可见）
+	DuplicatePolicy      string `mapstructure:"duplicate_policy"`       // 同一活动内上传完全相同文件时的处理方式：reject（拒绝）或 flag（标记）
+	ResumableTTLHours    int    `mapstructure:"resumable_ttl_hours"`    // 断点续传的上传超过该时长（小时）未收到数据即过期
+	TrashRetentionDays   int    `mapstructure:"trash_retention_days"`   // 删除的作品在回收站中保留的天数，超过后彻底删除
+	MaxImagePixels       int64  `mapstructure:"max_image_pixels"`       // 允许解码的最大像素数（宽 × 高），超过的图片拒绝上传
+}
+
+// StorageConfig 文件存储配置
+type StorageConfig struct {
+	Driver string   `mapstructure:"driver"` // local（本地磁盘，目录为 upload.path）或 s3（S3 兼容对象存储）
+	S3     S3Config `mapstructure:"s3"`
+}
+
+// S3Config S3 兼容对象存储配置
+type S3Config struct {
+	Endpoint     string `mapstructure:"endpoint"` // 如 https://s3.us-east-1.amazonaws.com 或 http://localhost:9000
+	Region       string `mapstructure:"region"`
+	Bucket       string `mapstructure:"bucket"`
+	AccessKey    string `mapstructure:"access_key"`
+	SecretKey    string `mapstructure:"secret_key"`
+	UsePathStyle bool   `mapstructure:"use_path_style"` // 使用 endpoint/bucket 形式的地址（MinIO 等需要开启）
+}
+
+// ReviewConfig 审核配置
+type ReviewConfig struct {
+	LeaseTTLMinutes int `mapstructure:"lease_ttl_minutes"` // 审核员领取作品的租约时长（分钟）
+	MaxClaimSize    int `mapstructure:"max_claim_size"`    // 单次最多领取的作品数量
+}
+
+// ExportConfig 表格导出配置
+type ExportConfig struct {
+	HeaderLanguage string `mapstructure:"header_language"` // 导出表格的默认表头语言：zh（中文）或 en（英文），请求可用 lang 参数覆盖
+}
+
+// VotingConfig 公众投票配置
+type VotingConfig struct {
+	SyncIntervalMinutes int `mapstructure:"sync_interval_minutes"` // 将 Redis 中的实时票数同步到数据库的间隔（分钟）
 }
 
 // EmailConfig 邮件配置
@@ -135,6 +184,17 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("jwt expire_hours must be positive")
 	}
 
+	// 验证签名图片链接配置（未配置密钥时不签发签名链接）
+	if c.ImageURL.Secret != "" && len(c.ImageURL.Secret) < 32 {
+		return fmt.Errorf("image_url secret must be at least 32 characters")
+	}
+	if c.ImageURL.TTLMinutes < 0 {
+		return fmt.Errorf("image_url ttl_minutes must not be negative")
+	}
+	if c.ImageURL.TTLMinutes == 0 {
+		c.ImageURL.TTLMinutes = 60
+	}
+
 	// 验证上传配置
 	if c.Upload.Path == "" {
 		return fmt.Errorf("upload path is required")
@@ -142,6 +202,83 @@ func (c *Config) Validate() error {
 	if c.Upload.MaxSize <= 0 {
 		return fmt.Errorf("upload max_size must be positive")
 	}
+	if c.Upload.DuplicatePolicy == "" {
+		c.Upload.DuplicatePolicy = "reject"
+	}
+	if c.Upload.DuplicatePolicy != "reject" && c.Upload.DuplicatePolicy != "flag" {
+		return fmt.Errorf("invalid upload duplicate_policy: %s (must be 'reject' or 'flag')", c.Upload.DuplicatePolicy)
+	}
+	if c.Upload.ResumableTTLHours < 0 {
+		return fmt.Errorf("upload resumable_ttl_hours must not be negative")
+	}
+	if c.Upload.ResumableTTLHours == 0 {
+		c.Upload.ResumableTTLHours = 24
+	}
+	if c.Upload.TrashRetentionDays < 0 {
+		return fmt.Errorf("upload trash_retention_days must not be negative")
+	}
+	if c.Upload.TrashRetentionDays == 0 {
+		c.Upload.TrashRetentionDays = 30
+	}
+	if c.Upload.MaxImagePixels < 0 {
+		return fmt.Errorf("upload max_image_pixels must not be negative")
+	}
+	if c.Upload.MaxImagePixels == 0 {
+		c.Upload.MaxImagePixels = 100000000
+	}
+
+	// 验证存储配置（未配置时使用本地磁盘）
+	if c.Storage.Driver == "" {
+		c.Storage.Driver = "local"
+	}
+	switch c.Storage.Driver {
+	case "local":
+	case "s3":
+		if c.Storage.S3.Endpoint == "" {
+			return fmt.Errorf("storage s3 endpoint is required")
+		}
+		if c.Storage.S3.Bucket == "" {
+			return fmt.Errorf("storage s3 bucket is required")
+		}
+		if c.Storage.S3.AccessKey == "" || c.Storage.S3.SecretKey == "" {
+			return fmt.Errorf("storage s3 access_key and secret_key are required")
+		}
+		if c.Storage.S3.Region == "" {
+			c.Storage.S3.Region = "us-east-1"
+		}
+	default:
+		return fmt.Errorf("invalid storage driver: %s (must be 'local' or 's3')", c.Storage.Driver)
+	}
+
+	// 验证审核配置（未配置时使用默认值）
+	if c.Review.LeaseTTLMinutes < 0 {
+		return fmt.Errorf("review lease_ttl_minutes must not be negative")
+	}
+	if c.Review.LeaseTTLMinutes == 0 {
+		c.Review.LeaseTTLMinutes = 15
+	}
+	if c.Review.MaxClaimSize < 0 {
+		return fmt.Errorf("review max_claim_size must not be negative")
+	}
+	if c.Review.MaxClaimSize == 0 {
+		c.Review.MaxClaimSize = 50
+	}
+
+	// 验证投票配置（未配置时使用默认值）
+	if c.Voting.SyncIntervalMinutes < 0 {
+		return fmt.Errorf("voting sync_interval_minutes must not be negative")
+	}
+	if c.Voting.SyncIntervalMinutes == 0 {
+		c.Voting.SyncIntervalMinutes = 5
+	}
+
+	// 验证导出配置（未配置时使用中文表头）
+	if c.Export.HeaderLanguage == "" {
+		c.Export.HeaderLanguage = "zh"
+	}
+	if c.Export.HeaderLanguage != "zh" && c.Export.HeaderLanguage != "en" {
+		return fmt.Errorf("invalid export header_language: %s (must be 'zh' or 'en')", c.Export.HeaderLanguage)
+	}
 
 	// 验证日志配置
 	validLogLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
@@ -157,6 +294,50 @@ func (c *Config) GetJWTExpireDuration() time.Duration {
 	return time.Duration(c.JWT.ExpireHours) * time.Hour
 }
 
+// GetImageURLTTL 获取签名图片链接的最短有效期
+func (c *Config) GetImageURLTTL() time.Duration {
+	return time.Duration(c.ImageURL.TTLMinutes) * time.Minute
+}
+
+// GetResumableUploadTTL 获取断点续传上传的过期时长
+func (c *Config) GetResumableUploadTTL() time.Duration {
+	return time.Duration(c.Upload.ResumableTTLHours) * time.Hour
+}
+
+// GetTrashRetention 获取删除的作品在回收站中的保留时长
+func (c *Config) GetTrashRetention() time.Duration {
+	return time.Duration(c.Upload.TrashRetentionDays) * 24 * time.Hour
+}
+
+// GetReviewLeaseDuration 获取审核租约时长
+func (c *Config) GetReviewLeaseDuration() time.Duration {
+	return time.Duration(c.Review.LeaseTTLMinutes) * time.Minute
+}
+
+// GetVoteSyncInterval 获取票数同步间隔
+func (c *Config) GetVoteSyncInterval() time.Duration {
+	return time.Duration(c.Voting.SyncIntervalMinutes) * time.Minute
+}
+
+// GetStorageConfig 获取指定驱动的存储配置，driver 为空时使用配置的驱动
+func (c *Config) GetStorageConfig(driver string) storage.Config {
+	if driver == "" {
+		driver = c.Storage.Driver
+	}
+	return storage.Config{
+		Driver:    driver,
+		LocalPath: c.Upload.Path,
+		S3: storage.S3Config{
+			Endpoint:     c.Storage.S3.Endpoint,
+			Region:       c.Storage.S3.Region,
+			Bucket:       c.Storage.S3.Bucket,
+			AccessKey:    c.Storage.S3.AccessKey,
+			SecretKey:    c.Storage.S3.SecretKey,
+			UsePathStyle: c.Storage.S3.UsePathStyle,
+		},
+	}
+}
+
 // GetMySQLDSN 获取MySQL连接字符串
 func (c *Config) GetMySQLDSN() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
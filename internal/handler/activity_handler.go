@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"art-collection-system/internal/models"
 	"art-collection-system/internal/service"
 	"art-collection-system/internal/utils"
+	"encoding/json"
+	"errors"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +31,14 @@ type CreateActivityRequest struct {
 	Description       string  `json:"description"`
 	Deadline          *string `json:"deadline"`
 	MaxUploadsPerUser int     `json:"max_uploads_per_user"`
+	GalleryEnabled    bool    `json:"gallery_enabled"`
+	GalleryOpensAt    *string `json:"gallery_opens_at"`
+	VotingEnabled     bool    `json:"voting_enabled"`
+	VotingOpensAt     *string `json:"voting_opens_at"`
+	VotingClosesAt    *string `json:"voting_closes_at"`
+	MaxVotesPerUser   int     `json:"max_votes_per_user"`
+
+	ImageConstraints models.ImageConstraints `json:"image_constraints"`
 }
 
 // CreateActivity creates a new activity (admin only)
@@ -50,16 +61,49 @@ func (h *ActivityHandler) CreateActivity(c *gin.Context) {
 		deadline = &parsedTime
 	}
 
+	// Parse gallery opening time if provided
+	galleryOpensAt, err := parseOptionalTime(req.GalleryOpensAt)
+	if err != nil {
+		utils.Error(c, 400, "展示开放时间格式不正确，请使用 RFC3339 格式")
+		return
+	}
+
+	// Parse the voting window if provided
+	votingOpensAt, err := parseOptionalTime(req.VotingOpensAt)
+	if err != nil {
+		utils.Error(c, 400, "投票开始时间格式不正确，请使用 RFC3339 格式")
+		return
+	}
+	votingClosesAt, err := parseOptionalTime(req.VotingClosesAt)
+	if err != nil {
+		utils.Error(c, 400, "投票结束时间格式不正确，请使用 RFC3339 格式")
+		return
+	}
+
 	// Set default max uploads if not provided
 	maxUploads := req.MaxUploadsPerUser
 	if maxUploads <= 0 {
 		maxUploads = 5
 	}
 
+	settings := service.ActivitySettings{
+		GalleryEnabled:   req.GalleryEnabled,
+		GalleryOpensAt:   galleryOpensAt,
+		ImageConstraints: req.ImageConstraints,
+		VotingEnabled:    req.VotingEnabled,
+		VotingOpensAt:    votingOpensAt,
+		VotingClosesAt:   votingClosesAt,
+		MaxVotesPerUser:  req.MaxVotesPerUser,
+	}
+
 	// Create activity
-	activity, err := h.activityService.CreateActivity(req.Name, req.Description, deadline, maxUploads)
+	activity, err := h.activityService.CreateActivity(req.Name, req.Description, deadline, maxUploads, settings)
 	if err != nil {
-		utils.Error(c, 500, "创建活动失败")
+		if errors.Is(err, service.ErrInvalidImageConstraints) || errors.Is(err, service.ErrInvalidVotingSettings) {
+			utils.Error(c, 400, err.Error())
+		} else {
+			utils.Error(c, 500, "创建活动失败")
+		}
 		return
 	}
 
@@ -72,6 +116,53 @@ type UpdateActivityRequest struct {
 	Description       string  `json:"description"`
 	Deadline          *string `json:"deadline"`
 	MaxUploadsPerUser int     `json:"max_uploads_per_user"`
+
+	// Settings left out of the request keep their current value
+	GalleryEnabled  *bool        `json:"gallery_enabled"`
+	GalleryOpensAt  nullableTime `json:"gallery_opens_at"`
+	VotingEnabled   *bool        `json:"voting_enabled"`
+	VotingOpensAt   nullableTime `json:"voting_opens_at"`
+	VotingClosesAt  nullableTime `json:"voting_closes_at"`
+	MaxVotesPerUser *int         `json:"max_votes_per_user"`
+
+	ImageConstraints *models.ImageConstraints `json:"image_constraints"`
+}
+
+// nullableTime is an optional RFC3339 time request field that tells an omitted field
+// apart from an explicit null
+type nullableTime struct {
+	Set   bool
+	Value *string
+}
+
+// UnmarshalJSON records that the field was present in the request
+func (t *nullableTime) UnmarshalJSON(data []byte) error {
+	t.Set = true
+	return json.Unmarshal(data, &t.Value)
+}
+
+// parse parses the field for a settings update: nil when omitted, a pointer to nil when null or empty
+func (t nullableTime) parse() (**time.Time, error) {
+	if !t.Set {
+		return nil, nil
+	}
+	parsedTime, err := parseOptionalTime(t.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsedTime, nil
+}
+
+// parseOptionalTime parses an optional RFC3339 time from a request field
+func parseOptionalTime(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	parsedTime, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsedTime, nil
 }
 
 // UpdateActivity updates an existing activity (admin only)
@@ -102,10 +193,41 @@ func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
 		deadline = &parsedTime
 	}
 
+	// Parse gallery opening time if provided
+	galleryOpensAt, err := req.GalleryOpensAt.parse()
+	if err != nil {
+		utils.Error(c, 400, "展示开放时间格式不正确，请使用 RFC3339 格式")
+		return
+	}
+
+	// Parse the voting window if provided
+	votingOpensAt, err := req.VotingOpensAt.parse()
+	if err != nil {
+		utils.Error(c, 400, "投票开始时间格式不正确，请使用 RFC3339 格式")
+		return
+	}
+	votingClosesAt, err := req.VotingClosesAt.parse()
+	if err != nil {
+		utils.Error(c, 400, "投票结束时间格式不正确，请使用 RFC3339 格式")
+		return
+	}
+
+	update := service.ActivitySettingsUpdate{
+		GalleryEnabled:   req.GalleryEnabled,
+		GalleryOpensAt:   galleryOpensAt,
+		ImageConstraints: req.ImageConstraints,
+		VotingEnabled:    req.VotingEnabled,
+		VotingOpensAt:    votingOpensAt,
+		VotingClosesAt:   votingClosesAt,
+		MaxVotesPerUser:  req.MaxVotesPerUser,
+	}
+
 	// Update activity
-	if err := h.activityService.UpdateActivity(uint(activityID), req.Name, req.Description, deadline, req.MaxUploadsPerUser); err != nil {
+	if err := h.activityService.UpdateActivity(uint(activityID), req.Name, req.Description, deadline, req.MaxUploadsPerUser, update); err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.Error(c, 404, "活动不存在")
+		} else if errors.Is(err, service.ErrInvalidImageConstraints) || errors.Is(err, service.ErrInvalidVotingSettings) {
+			utils.Error(c, 400, err.Error())
 		} else {
 			utils.Error(c, 500, "更新活动失败")
 		}
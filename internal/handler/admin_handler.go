@@ -1,31 +1,49 @@
 package handler
 
 import (
+	"art-collection-system/internal/models"
 	"art-collection-system/internal/service"
 	"art-collection-system/internal/utils"
+	"errors"
+	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AdminHandler handles administrator-related HTTP requests
 type AdminHandler struct {
-	artworkService *service.ArtworkService
-	adminService   *service.AdminService
+	artworkService  *service.ArtworkService
+	adminService    *service.AdminService
+	leaseService    *service.ReviewLeaseService
+	imageURLService *service.ImageURLService
+	storageService  *service.StorageCheckService
 }
 
 // NewAdminHandler creates a new admin handler instance
-func NewAdminHandler(artworkService *service.ArtworkService, adminService *service.AdminService) *AdminHandler {
+func NewAdminHandler(artworkService *service.ArtworkService, adminService *service.AdminService, leaseService *service.ReviewLeaseService, imageURLService *service.ImageURLService, storageService *service.StorageCheckService) *AdminHandler {
 	return &AdminHandler{
-		artworkService: artworkService,
-		adminService:   adminService,
+		artworkService:  artworkService,
+		adminService:    adminService,
+		leaseService:    leaseService,
+		imageURLService: imageURLService,
+		storageService:  storageService,
 	}
 }
 
 // GetReviewQueue retrieves the list of artworks pending review
+// Artworks claimed by other reviewers are hidden
 // GET /api/v1/admin/review-queue
 func (h *AdminHandler) GetReviewQueue(c *gin.Context) {
+	// Get reviewer ID from context
+	reviewerID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
 	// Get pagination parameters
 	pageStr := c.DefaultQuery("page", "1")
 	pageSizeStr := c.DefaultQuery("page_size", "20")
@@ -45,29 +63,211 @@ func (h *AdminHandler) GetReviewQueue(c *gin.Context) {
 		pageSize = 100
 	}
 
+	// Hide artworks claimed by other reviewers
+	leasedIDs, err := h.leaseService.LeasedByOthers(reviewerID.(uint))
+	if err != nil {
+		utils.Error(c, 500, "获取审核队列失败")
+		return
+	}
+
 	// Get review queue
-	artworks, total, err := h.artworkService.GetReviewQueue(page, pageSize)
+	artworks, total, err := h.artworkService.GetReviewQueue(page, pageSize, leasedIDs)
 	if err != nil {
 		utils.Error(c, 500, "获取审核队列失败")
 		return
 	}
 
+	// Show reviewers which artworks may duplicate earlier submissions
+	items, err := h.artworkService.WithPossibleDuplicates(artworks)
+	if err != nil {
+		utils.Error(c, 500, "获取审核队列失败")
+		return
+	}
+	h.imageURLService.SignReviewQueueItems(items)
+
 	utils.Success(c, gin.H{
-		"artworks":  artworks,
+		"artworks":  items,
 		"total":     total,
 		"page":      page,
 		"page_size": pageSize,
 	})
 }
 
+// respondLeaseError maps review lease errors to HTTP responses
+func respondLeaseError(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "已被其他审核员领取") {
+		utils.Error(c, 409, err.Error())
+	} else {
+		utils.Error(c, 500, "检查审核领取状态失败")
+	}
+}
+
+// ClaimReviewQueueRequest represents the request body for claiming artworks from the review queue
+type ClaimReviewQueueRequest struct {
+	Count int `json:"count"`
+}
+
+// ClaimReviewQueue leases the next pending artworks to the current reviewer
+// POST /api/v1/admin/review-queue/claim
+func (h *AdminHandler) ClaimReviewQueue(c *gin.Context) {
+	// Get reviewer ID from context
+	reviewerID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// The request body is optional; an empty body claims the default number of artworks
+	var req ClaimReviewQueueRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.Error(c, 400, "参数错误")
+		return
+	}
+
+	artworks, expiresAt, err := h.leaseService.ClaimNext(reviewerID.(uint), req.Count)
+	if err != nil {
+		utils.Error(c, 500, "领取审核作品失败")
+		return
+	}
+
+	items, err := h.artworkService.WithPossibleDuplicates(artworks)
+	if err != nil {
+		utils.Error(c, 500, "领取审核作品失败")
+		return
+	}
+	h.imageURLService.SignReviewQueueItems(items)
+
+	utils.Success(c, gin.H{
+		"artworks":   items,
+		"count":      len(items),
+		"expires_at": expiresAt,
+	})
+}
+
+// GetClaimedArtworks retrieves the artworks currently claimed by the current reviewer
+// GET /api/v1/admin/review-queue/claims
+func (h *AdminHandler) GetClaimedArtworks(c *gin.Context) {
+	// Get reviewer ID from context
+	reviewerID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	artworks, err := h.leaseService.GetClaimed(reviewerID.(uint))
+	if err != nil {
+		utils.Error(c, 500, "获取已领取作品失败")
+		return
+	}
+
+	items, err := h.artworkService.WithPossibleDuplicates(artworks)
+	if err != nil {
+		utils.Error(c, 500, "获取已领取作品失败")
+		return
+	}
+	h.imageURLService.SignReviewQueueItems(items)
+
+	utils.Success(c, gin.H{
+		"artworks": items,
+		"count":    len(items),
+	})
+}
+
+// ReleaseReviewQueueRequest represents the request body for releasing claimed artworks
+type ReleaseReviewQueueRequest struct {
+	ArtworkIDs []uint `json:"artwork_ids" binding:"required"`
+}
+
+// ReleaseReviewQueue releases the current reviewer's claims on artworks
+// POST /api/v1/admin/review-queue/release
+func (h *AdminHandler) ReleaseReviewQueue(c *gin.Context) {
+	// Get reviewer ID from context
+	reviewerID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	var req ReleaseReviewQueueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, 400, "参数错误")
+		return
+	}
+
+	released, err := h.leaseService.Release(reviewerID.(uint), req.ArtworkIDs)
+	if err != nil {
+		if strings.Contains(err.Error(), "不能为空") {
+			utils.Error(c, 400, err.Error())
+		} else {
+			utils.Error(c, 500, "释放审核作品失败")
+		}
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"message":  "释放成功",
+		"released": released,
+	})
+}
+
 // ReviewArtworkRequest represents the request body for reviewing an artwork
+// Status takes precedence over Approved; Reason is required when Status is "rejected"
 type ReviewArtworkRequest struct {
-	Approved bool `json:"approved"`
+	Approved    bool                      `json:"approved"`
+	Status      string                    `json:"status"`
+	Reason      string                    `json:"reason"`
+	ReasonCodes []models.RejectReasonCode `json:"reason_codes"`
+	Comment     string                    `json:"comment"`
+}
+
+// reviewDecision converts the request fields into a service review decision
+func reviewDecision(reviewerID uint, approved bool, status, reason string, reasonCodes []models.RejectReasonCode, comment string) service.ReviewDecision {
+	decision := service.ReviewDecision{
+		ReviewerID:  reviewerID,
+		Status:      models.ReviewStatus(status),
+		Reason:      reason,
+		ReasonCodes: reasonCodes,
+		Comment:     comment,
+	}
+
+	// Fall back to the legacy approved flag when no explicit status is given
+	if status == "" {
+		decision.Status = models.StatusPending
+		if approved {
+			decision.Status = models.StatusApproved
+		}
+	}
+
+	return decision
+}
+
+// reviewStatusText returns the display text for a review status
+func reviewStatusText(status models.ReviewStatus) string {
+	switch status {
+	case models.StatusApproved:
+		return "已审核"
+	case models.StatusRejected:
+		return "已驳回"
+	default:
+		return "未审核"
+	}
+}
+
+// isReviewValidationError reports whether a review error was caused by invalid input
+func isReviewValidationError(err error) bool {
+	return strings.Contains(err.Error(), "无效") || strings.Contains(err.Error(), "驳回原因")
 }
 
 // ReviewArtwork reviews a single artwork
 // PUT /api/v1/admin/artworks/:id/review
 func (h *AdminHandler) ReviewArtwork(c *gin.Context) {
+	// Get reviewer ID from context
+	reviewerID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
 	// Get artwork ID from URL parameter
 	artworkIDStr := c.Param("id")
 	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
@@ -82,9 +282,19 @@ func (h *AdminHandler) ReviewArtwork(c *gin.Context) {
 		return
 	}
 
+	decision := reviewDecision(reviewerID.(uint), req.Approved, req.Status, req.Reason, req.ReasonCodes, req.Comment)
+
+	// Artworks claimed by another reviewer cannot be reviewed
+	if err := h.leaseService.EnsureNotLeasedByOthers(reviewerID.(uint), []uint{uint(artworkID)}); err != nil {
+		respondLeaseError(c, err)
+		return
+	}
+
 	// Review artwork
-	if err := h.artworkService.ReviewArtwork(uint(artworkID), req.Approved); err != nil {
-		if strings.Contains(err.Error(), "不存在") {
+	if err := h.artworkService.ReviewArtwork(uint(artworkID), decision); err != nil {
+		if isReviewValidationError(err) {
+			utils.Error(c, 400, err.Error())
+		} else if strings.Contains(err.Error(), "不存在") {
 			utils.Error(c, 404, err.Error())
 		} else {
 			utils.Error(c, 500, "审核作品失败")
@@ -92,26 +302,35 @@ func (h *AdminHandler) ReviewArtwork(c *gin.Context) {
 		return
 	}
 
-	status := "未审核"
-	if req.Approved {
-		status = "已审核"
-	}
+	// Reviewed artworks no longer need to be held; a failure here only delays release until expiry
+	_ = h.leaseService.ReleaseReviewed([]uint{uint(artworkID)})
 
 	utils.Success(c, gin.H{
 		"message": "审核成功",
-		"status":  status,
+		"status":  reviewStatusText(decision.Status),
 	})
 }
 
 // BatchReviewArtworksRequest represents the request body for batch reviewing artworks
 type BatchReviewArtworksRequest struct {
-	ArtworkIDs []uint `json:"artwork_ids" binding:"required"`
-	Approved   bool   `json:"approved"`
+	ArtworkIDs  []uint                    `json:"artwork_ids" binding:"required"`
+	Approved    bool                      `json:"approved"`
+	Status      string                    `json:"status"`
+	Reason      string                    `json:"reason"`
+	ReasonCodes []models.RejectReasonCode `json:"reason_codes"`
+	Comment     string                    `json:"comment"`
 }
 
 // BatchReviewArtworks reviews multiple artworks at once
 // PUT /api/v1/admin/artworks/batch-review
 func (h *AdminHandler) BatchReviewArtworks(c *gin.Context) {
+	// Get reviewer ID from context
+	reviewerID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
 	var req BatchReviewArtworksRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.Error(c, 400, "参数错误")
@@ -130,21 +349,172 @@ func (h *AdminHandler) BatchReviewArtworks(c *gin.Context) {
 		return
 	}
 
-	// Batch review artworks
-	if err := h.artworkService.BatchReviewArtworks(req.ArtworkIDs, req.Approved); err != nil {
-		utils.Error(c, 500, "批量审核失败")
+	decision := reviewDecision(reviewerID.(uint), req.Approved, req.Status, req.Reason, req.ReasonCodes, req.Comment)
+
+	// Artworks claimed by another reviewer cannot be reviewed
+	if err := h.leaseService.EnsureNotLeasedByOthers(reviewerID.(uint), req.ArtworkIDs); err != nil {
+		respondLeaseError(c, err)
 		return
 	}
 
-	status := "未审核"
-	if req.Approved {
-		status = "已审核"
+	// Batch review artworks
+	count, err := h.artworkService.BatchReviewArtworks(req.ArtworkIDs, decision)
+	if err != nil {
+		if isReviewValidationError(err) {
+			utils.Error(c, 400, err.Error())
+		} else if strings.Contains(err.Error(), "不存在") {
+			utils.Error(c, 404, err.Error())
+		} else {
+			utils.Error(c, 500, "批量审核失败")
+		}
+		return
 	}
 
+	// Reviewed artworks no longer need to be held; a failure here only delays release until expiry
+	_ = h.leaseService.ReleaseReviewed(req.ArtworkIDs)
+
 	utils.Success(c, gin.H{
 		"message": "批量审核成功",
-		"count":   len(req.ArtworkIDs),
-		"status":  status,
+		"count":   count,
+		"status":  reviewStatusText(decision.Status),
+	})
+}
+
+// GetRejectReasons retrieves the predefined reject reason codes
+// GET /api/v1/admin/reject-reasons
+func (h *AdminHandler) GetRejectReasons(c *gin.Context) {
+	reasons := make([]gin.H, 0, len(models.RejectReasonCodes))
+	for _, code := range models.RejectReasonCodes {
+		reasons = append(reasons, gin.H{
+			"code":  code,
+			"label": models.RejectReasonLabels[code],
+		})
+	}
+
+	utils.Success(c, gin.H{"reasons": reasons})
+}
+
+// GetArtworkReviews retrieves the review timeline of an artwork
+// GET /api/v1/admin/artworks/:id/reviews
+func (h *AdminHandler) GetArtworkReviews(c *gin.Context) {
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	// Get review history
+	reviews, err := h.artworkService.GetReviewHistory(uint(artworkID))
+	if err != nil {
+		if strings.Contains(err.Error(), "不存在") {
+			utils.Error(c, 404, err.Error())
+		} else {
+			utils.Error(c, 500, "获取审核记录失败")
+		}
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"reviews": reviews,
+		"total":   len(reviews),
+	})
+}
+
+// GetPossibleDuplicates retrieves artworks whose file is identical or visually similar to an artwork's file
+// GET /api/v1/admin/artworks/:id/duplicates
+func (h *AdminHandler) GetPossibleDuplicates(c *gin.Context) {
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	duplicates, err := h.artworkService.GetPossibleDuplicates(uint(artworkID))
+	if err != nil {
+		if strings.Contains(err.Error(), "不存在") {
+			utils.Error(c, 404, err.Error())
+		} else {
+			utils.Error(c, 500, "获取疑似重复作品失败")
+		}
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"duplicates": duplicates,
+		"total":      len(duplicates),
+	})
+}
+
+// GetOriginalMetadata retrieves the image metadata stripped from an artwork's file on upload
+// GET /api/v1/admin/artworks/:id/original-metadata
+func (h *AdminHandler) GetOriginalMetadata(c *gin.Context) {
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	metadata, err := h.artworkService.GetOriginalMetadata(uint(artworkID))
+	if err != nil {
+		if strings.Contains(err.Error(), "不存在") {
+			utils.Error(c, 404, err.Error())
+		} else {
+			utils.Error(c, 500, "获取原始元数据失败")
+		}
+		return
+	}
+
+	utils.Success(c, gin.H{"metadata": metadata})
+}
+
+// GetReviewerActivity retrieves the review decisions made by a reviewer
+// GET /api/v1/admin/reviewers/:id/reviews
+func (h *AdminHandler) GetReviewerActivity(c *gin.Context) {
+	// Get reviewer ID from URL parameter
+	reviewerIDStr := c.Param("id")
+	reviewerID, err := strconv.ParseUint(reviewerIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的用户ID")
+		return
+	}
+
+	// Get pagination parameters
+	pageStr := c.DefaultQuery("page", "1")
+	pageSizeStr := c.DefaultQuery("page_size", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize <= 0 {
+		pageSize = 20
+	}
+
+	// Limit page size
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	// Get reviewer activity
+	reviews, total, err := h.artworkService.GetReviewerActivity(uint(reviewerID), page, pageSize)
+	if err != nil {
+		utils.Error(c, 500, "获取审核记录失败")
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"reviews":   reviews,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
 	})
 }
 
@@ -278,7 +648,10 @@ func (h *AdminHandler) GetActivityArtworks(c *gin.Context) {
 	}
 
 	// Get artworks for this activity
-	artworks, total, err := h.artworkService.GetArtworksByActivity(uint(activityID), page, pageSize)
+	// Optional tag filter
+	tag := c.Query("tag")
+
+	artworks, total, err := h.artworkService.GetArtworksByActivity(uint(activityID), page, pageSize, tag)
 	if err != nil {
 		if strings.Contains(err.Error(), "不存在") {
 			utils.Error(c, 404, err.Error())
@@ -288,6 +661,8 @@ func (h *AdminHandler) GetActivityArtworks(c *gin.Context) {
 		return
 	}
 
+	h.imageURLService.SignArtworks(artworks)
+
 	utils.Success(c, gin.H{
 		"artworks":  artworks,
 		"total":     total,
@@ -295,3 +670,22 @@ func (h *AdminHandler) GetActivityArtworks(c *gin.Context) {
 		"page_size": pageSize,
 	})
 }
+
+// CheckStorage lists orphaned files and records pointing at missing files
+// It only reports; cleanup is done with the check-storage command
+// GET /api/v1/admin/storage/check
+func (h *AdminHandler) CheckStorage(c *gin.Context) {
+	graceHours, err := strconv.Atoi(c.DefaultQuery("grace_hours", "24"))
+	if err != nil || graceHours < 0 {
+		utils.Error(c, 400, "无效的宽限期")
+		return
+	}
+
+	report, err := h.storageService.Check(c.Request.Context(), time.Duration(graceHours)*time.Hour, service.StorageActionReport)
+	if err != nil {
+		utils.Error(c, 500, "检查存储失败")
+		return
+	}
+
+	utils.Success(c, report)
+}
@@ -4,6 +4,8 @@ import (
 	"art-collection-system/internal/models"
 	"art-collection-system/internal/service"
 	"art-collection-system/internal/utils"
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -12,15 +14,20 @@ import (
 
 // ArtworkHandler handles artwork-related HTTP requests
 type ArtworkHandler struct {
-	artworkService *service.ArtworkService
-	fileService    *service.FileService
+	artworkService  *service.ArtworkService
+	fileService     *service.FileService
+	imageURLService *service.ImageURLService
+	maxUploadSize   int64
 }
 
 // NewArtworkHandler creates a new artwork handler instance
-func NewArtworkHandler(artworkService *service.ArtworkService, fileService *service.FileService) *ArtworkHandler {
+// maxUploadSize is the configured upload size limit in bytes
+func NewArtworkHandler(artworkService *service.ArtworkService, fileService *service.FileService, imageURLService *service.ImageURLService, maxUploadSize int64) *ArtworkHandler {
 	return &ArtworkHandler{
-		artworkService: artworkService,
-		fileService:    fileService,
+		artworkService:  artworkService,
+		fileService:     fileService,
+		imageURLService: imageURLService,
+		maxUploadSize:   maxUploadSize,
 	}
 }
 
@@ -47,40 +54,241 @@ func (h *ArtworkHandler) UploadArtwork(c *gin.Context) {
 		return
 	}
 
-	// Get uploaded file
-	file, header, err := c.Request.FormFile("file")
+	// Get and validate uploaded files (size, type, and content); several files make a multi-page artwork
+	files, err := openUploadedFiles(c, h.maxUploadSize)
 	if err != nil {
-		utils.Error(c, 400, "请上传文件")
+		utils.Error(c, 400, err.Error())
 		return
 	}
-	defer file.Close()
+	defer closeUploadedFiles(files)
 
-	// Validate file (size, type, and content)
-	if err := utils.ValidateImageFile(header); err != nil {
+	// Parse optional descriptive metadata
+	metadata, err := parseMetadataForm(c)
+	if err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	// A draft is only submitted for review later, via POST /api/v1/artworks/:id/submit
+	draft, err := parseDraftFlag(c.PostForm("draft"))
+	if err != nil {
 		utils.Error(c, 400, err.Error())
 		return
 	}
 
 	// Upload artwork
-	artwork, err := h.artworkService.UploadArtwork(userID.(uint), uint(activityID), file, header.Filename)
+	artwork, err := h.artworkService.UploadArtwork(userID.(uint), uint(activityID), files, metadata, draft)
 	if err != nil {
-		if strings.Contains(err.Error(), "活动") {
-			utils.Error(c, 400, err.Error())
-		} else if strings.Contains(err.Error(), "上传数量") {
-			utils.Error(c, 400, err.Error())
-		} else {
-			utils.Error(c, 500, "上传作品失败")
-		}
+		respondUploadError(c, err)
 		return
 	}
 
-	utils.Success(c, gin.H{
+	h.imageURLService.SignArtwork(artwork)
+
+	utils.Success(c, uploadedArtworkResponse(artwork))
+}
+
+// openUploadedFiles opens the files sent in the "file" fields of a multipart form, in order,
+// after validating each one. The caller must close them with closeUploadedFiles.
+func openUploadedFiles(c *gin.Context, maxSize int64) ([]service.UploadedFile, error) {
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["file"]) == 0 {
+		return nil, errors.New("请上传文件")
+	}
+
+	files := make([]service.UploadedFile, 0, len(form.File["file"]))
+	for _, header := range form.File["file"] {
+		if err := utils.ValidateImageFile(header, maxSize); err != nil {
+			closeUploadedFiles(files)
+			return nil, err
+		}
+		file, err := header.Open()
+		if err != nil {
+			closeUploadedFiles(files)
+			return nil, errors.New("请上传文件")
+		}
+		files = append(files, service.UploadedFile{File: file, Filename: header.Filename})
+	}
+	return files, nil
+}
+
+// closeUploadedFiles closes files opened by openUploadedFiles
+func closeUploadedFiles(files []service.UploadedFile) {
+	for _, file := range files {
+		file.File.Close()
+	}
+}
+
+// respondUploadError maps artwork upload errors to HTTP responses
+func respondUploadError(c *gin.Context, err error) {
+	if isMetadataValidationError(err) || isImageValidationError(err) {
+		utils.Error(c, 400, err.Error())
+	} else if strings.Contains(err.Error(), "活动") {
+		utils.Error(c, 400, err.Error())
+	} else if strings.Contains(err.Error(), "上传数量") {
+		utils.Error(c, 400, err.Error())
+	} else {
+		utils.Error(c, 500, "上传作品失败")
+	}
+}
+
+// uploadedArtworkResponse builds the response body for a newly uploaded artwork
+func uploadedArtworkResponse(artwork *models.Artwork) gin.H {
+	return gin.H{
 		"id":            artwork.ID,
 		"activity_id":   artwork.ActivityID,
 		"file_name":     artwork.FileName,
+		"page_count":    artwork.PageCount,
+		"pages":         artwork.Pages,
+		"title":         artwork.Title,
+		"description":   artwork.Description,
+		"medium":        artwork.Medium,
+		"width_cm":      artwork.WidthCM,
+		"height_cm":     artwork.HeightCM,
+		"depth_cm":      artwork.DepthCM,
+		"creation_year": artwork.CreationYear,
+		"tags":          artwork.Tags,
 		"review_status": artwork.ReviewStatus,
+		"image_urls":    artwork.ImageURLs,
 		"created_at":    artwork.CreatedAt,
+	}
+}
+
+// parseDraftFlag reads the optional flag saving an upload as a draft
+func parseDraftFlag(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	draft, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("无效的草稿标记")
+	}
+	return draft, nil
+}
+
+// parseMetadataForm reads the optional artwork metadata fields of a multipart upload form
+// Tags may be sent as repeated "tags" fields or as a single comma-separated value
+func parseMetadataForm(c *gin.Context) (service.ArtworkMetadata, error) {
+	return parseMetadataFields(c.PostForm, c.PostFormArray("tags"))
+}
+
+// parseMetadataFields reads the optional artwork metadata fields through get
+// Each of tags may hold several comma-separated tags
+func parseMetadataFields(get func(key string) string, tags []string) (service.ArtworkMetadata, error) {
+	metadata := service.ArtworkMetadata{
+		Title:       get("title"),
+		Description: get("description"),
+		Medium:      get("medium"),
+	}
+
+	dimensions := []struct {
+		field  string
+		target **float64
+	}{
+		{"width_cm", &metadata.WidthCM},
+		{"height_cm", &metadata.HeightCM},
+		{"depth_cm", &metadata.DepthCM},
+	}
+	for _, dimension := range dimensions {
+		value := strings.TrimSpace(get(dimension.field))
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return metadata, errors.New("无效的作品尺寸")
+		}
+		*dimension.target = &parsed
+	}
+
+	if value := strings.TrimSpace(get("creation_year")); value != "" {
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			return metadata, errors.New("无效的创作年份")
+		}
+		metadata.CreationYear = &year
+	}
+
+	for _, value := range tags {
+		metadata.Tags = append(metadata.Tags, strings.Split(value, ",")...)
+	}
+
+	return metadata, nil
+}
+
+// isMetadataValidationError reports whether an error was caused by invalid artwork metadata
+func isMetadataValidationError(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "不能超过") || strings.Contains(message, "必须在")
+}
+
+// isImageValidationError reports whether an error was caused by an unacceptable image file
+func isImageValidationError(err error) bool {
+	return errors.Is(err, utils.ErrInvalidImageData) || errors.Is(err, utils.ErrImageTooLarge) || errors.Is(err, service.ErrImageConstraint) ||
+		errors.Is(err, utils.ErrFileTooLarge) || errors.Is(err, utils.ErrInvalidFileType) || errors.Is(err, utils.ErrInvalidFileHeader)
+}
+
+// UpdateArtworkRequest represents the request body for editing an artwork's metadata
+type UpdateArtworkRequest struct {
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Medium       string   `json:"medium"`
+	WidthCM      *float64 `json:"width_cm"`
+	HeightCM     *float64 `json:"height_cm"`
+	DepthCM      *float64 `json:"depth_cm"`
+	CreationYear *int     `json:"creation_year"`
+	Tags         []string `json:"tags"`
+}
+
+// UpdateArtwork replaces the descriptive metadata of an artwork
+// PUT /api/v1/artworks/:id
+func (h *ArtworkHandler) UpdateArtwork(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	var req UpdateArtworkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, 400, "参数错误")
+		return
+	}
+
+	artwork, err := h.artworkService.UpdateArtworkMetadata(uint(artworkID), userID.(uint), service.ArtworkMetadata{
+		Title:        req.Title,
+		Description:  req.Description,
+		Medium:       req.Medium,
+		WidthCM:      req.WidthCM,
+		HeightCM:     req.HeightCM,
+		DepthCM:      req.DepthCM,
+		CreationYear: req.CreationYear,
+		Tags:         req.Tags,
 	})
+	if err != nil {
+		if strings.Contains(err.Error(), "权限") {
+			utils.Error(c, 403, err.Error())
+		} else if strings.Contains(err.Error(), "不存在") {
+			utils.Error(c, 404, err.Error())
+		} else if isMetadataValidationError(err) {
+			utils.Error(c, 400, err.Error())
+		} else {
+			utils.Error(c, 500, "更新作品信息失败")
+		}
+		return
+	}
+
+	h.imageURLService.SignArtwork(artwork)
+	utils.Success(c, artwork)
 }
 
 // DeleteArtwork deletes an artwork
@@ -123,6 +331,111 @@ func (h *ArtworkHandler) DeleteArtwork(c *gin.Context) {
 	utils.Success(c, gin.H{"message": "删除成功"})
 }
 
+// GetTrash lists the current user's artworks in the trash
+// GET /api/v1/artworks/trash
+func (h *ArtworkHandler) GetTrash(c *gin.Context) {
+	h.getTrash(c, false)
+}
+
+// GetAllTrash lists every author's artworks in the trash (admin only)
+// GET /api/v1/admin/artworks/trash
+func (h *ArtworkHandler) GetAllTrash(c *gin.Context) {
+	h.getTrash(c, true)
+}
+
+// getTrash lists artworks in the trash with pagination
+func (h *ArtworkHandler) getTrash(c *gin.Context, allUsers bool) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get user role from context
+	userRole, exists := c.Get("user_role")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get pagination parameters
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	artworks, total, err := h.artworkService.GetTrash(userID.(uint), userRole.(string), allUsers, page, pageSize)
+	if err != nil {
+		if strings.Contains(err.Error(), "权限") {
+			utils.Error(c, 403, err.Error())
+		} else {
+			utils.Error(c, 500, "获取回收站失败")
+		}
+		return
+	}
+
+	h.imageURLService.SignArtworks(artworks)
+
+	utils.Success(c, gin.H{
+		"artworks":       artworks,
+		"total":          total,
+		"page":           page,
+		"page_size":      pageSize,
+		"retention_days": int(h.artworkService.TrashRetention().Hours() / 24),
+	})
+}
+
+// RestoreArtwork takes an artwork out of the trash (owner or admin)
+// POST /api/v1/artworks/:id/restore
+func (h *ArtworkHandler) RestoreArtwork(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get user role from context
+	userRole, exists := c.Get("user_role")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	artwork, err := h.artworkService.RestoreArtwork(uint(artworkID), userID.(uint), userRole.(string))
+	if err != nil {
+		if strings.Contains(err.Error(), "权限") {
+			utils.Error(c, 403, err.Error())
+		} else if strings.Contains(err.Error(), "回收站中不存在") {
+			utils.Error(c, 404, err.Error())
+		} else if strings.Contains(err.Error(), "活动") || strings.Contains(err.Error(), "上传数量") {
+			utils.Error(c, 400, err.Error())
+		} else {
+			utils.Error(c, 500, "恢复作品失败")
+		}
+		return
+	}
+
+	h.imageURLService.SignArtwork(artwork)
+	utils.Success(c, artwork)
+}
+
 // GetArtwork retrieves artwork information with permission check
 // GET /api/v1/artworks/:id
 func (h *ArtworkHandler) GetArtwork(c *gin.Context) {
@@ -160,11 +473,14 @@ func (h *ArtworkHandler) GetArtwork(c *gin.Context) {
 		return
 	}
 
+	if artwork, ok := artwork.(*models.Artwork); ok {
+		h.imageURLService.SignArtwork(artwork)
+	}
 	utils.Success(c, artwork)
 }
 
 // ServeImage serves the artwork image file with permission check
-// GET /api/v1/artworks/:id/image
+// GET /api/v1/artworks/:id/image?size=thumb|preview|original
 func (h *ArtworkHandler) ServeImage(c *gin.Context) {
 	// Get requester info from context
 	requesterID, exists := c.Get("user_id")
@@ -187,8 +503,14 @@ func (h *ArtworkHandler) ServeImage(c *gin.Context) {
 		return
 	}
 
-	// Serve file through proxy (permission check is done inside ServeFile)
-	// We need to get the artwork first to get the file path
+	// Get requested rendition, defaulting to the original
+	size := c.DefaultQuery("size", service.RenditionOriginal)
+	if !service.IsValidRendition(size) {
+		utils.Error(c, 400, "无效的图片尺寸，可选值为 thumb、preview、original")
+		return
+	}
+
+	// Check permission and get the file path
 	artworkInterface, err := h.artworkService.GetArtwork(uint(artworkID), requesterID.(uint), requesterRole.(string))
 	if err != nil {
 		if strings.Contains(err.Error(), "权限") || strings.Contains(err.Error(), "permission") {
@@ -208,17 +530,427 @@ func (h *ArtworkHandler) ServeImage(c *gin.Context) {
 		return
 	}
 
-	// Serve file through proxy
-	fileData, contentType, err := h.fileService.ServeFile(artwork.FilePath, uint(artworkID), requesterID.(uint), requesterRole.(string), h.artworkService)
+	// Stream the file; the permission check above runs on every request, including revalidations
+	serveStoredFile(c, h.fileService, h.fileService.ResolveRendition(artwork.FilePath, size), privateImageCacheControl)
+}
+
+// ServeSignedImage serves an artwork image through a signed, expiring URL without authentication
+// GET /api/v1/artworks/:id/signed-image?size=thumb|preview|original&v=...&expires=...&signature=...
+func (h *ArtworkHandler) ServeSignedImage(c *gin.Context) {
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	h.serveSignedImage(c, uint(artworkID), 1)
+}
+
+// ServeSignedPageImage serves one page of a multi-page artwork through a signed, expiring URL without authentication
+// GET /api/v1/artworks/:id/pages/:page/signed-image?size=thumb|preview|original&v=...&expires=...&signature=...
+func (h *ArtworkHandler) ServeSignedPageImage(c *gin.Context) {
+	// Get artwork ID and page number from URL parameters
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	page, err := strconv.Atoi(c.Param("page"))
+	if err != nil || page <= 0 {
+		utils.Error(c, 400, "无效的页码")
+		return
+	}
+
+	h.serveSignedImage(c, uint(artworkID), page)
+}
+
+// serveSignedImage verifies a signed image URL and streams the requested page
+func (h *ArtworkHandler) serveSignedImage(c *gin.Context, artworkID uint, page int) {
+	size := c.DefaultQuery("size", service.RenditionOriginal)
+	if !service.IsValidRendition(size) {
+		utils.Error(c, 400, "无效的图片尺寸，可选值为 thumb、preview、original")
+		return
+	}
+
+	// The signature binds the artwork ID, page, rendition, file version and expiry, so none of them can be altered
+	remaining, err := h.imageURLService.Verify(artworkID, page, size, c.Query("v"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidImageSignature) || errors.Is(err, utils.ErrImageURLExpired) {
+			utils.Error(c, 403, err.Error())
+		} else {
+			utils.Error(c, 500, "校验图片链接失败")
+		}
+		return
+	}
+
+	filePath, err := h.artworkService.GetArtworkFilePath(artworkID, page)
+	if err != nil {
+		utils.Error(c, 404, err.Error())
+		return
+	}
+
+	// The URL itself is the credential, so browsers may reuse the response until it expires
+	cacheControl := fmt.Sprintf("private, max-age=%d", int(remaining.Seconds()))
+	serveStoredFile(c, h.fileService, h.fileService.ResolveRendition(filePath, size), cacheControl)
+}
+
+// ReplaceArtworkFile replaces the file of an artwork with a new revision
+// PUT /api/v1/artworks/:id/file
+func (h *ArtworkHandler) ReplaceArtworkFile(c *gin.Context) {
+	h.replaceFile(c, 1)
+}
+
+// ReplacePageFile replaces the file of a page of a multi-page artwork with a new revision
+// PUT /api/v1/artworks/:id/pages/:page/file
+func (h *ArtworkHandler) ReplacePageFile(c *gin.Context) {
+	page, err := strconv.Atoi(c.Param("page"))
+	if err != nil || page <= 0 {
+		utils.Error(c, 400, "无效的页码")
+		return
+	}
+
+	h.replaceFile(c, page)
+}
+
+// replaceFile replaces the file of a page of the artwork in the URL with the uploaded file
+func (h *ArtworkHandler) replaceFile(c *gin.Context, page int) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	// Get uploaded file
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.Error(c, 400, "请上传文件")
+		return
+	}
+	defer file.Close()
+
+	// Validate file (size, type, and content)
+	if err := utils.ValidateImageFile(header, h.maxUploadSize); err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	// Replace the page's file
+	artwork, err := h.artworkService.ReplaceArtworkFile(uint(artworkID), userID.(uint), page, file, header.Filename)
+	if err != nil {
+		if strings.Contains(err.Error(), "权限") {
+			utils.Error(c, 403, err.Error())
+		} else if strings.Contains(err.Error(), "作品不存在") || strings.Contains(err.Error(), "作品页面不存在") {
+			utils.Error(c, 404, err.Error())
+		} else if strings.Contains(err.Error(), "活动") || isImageValidationError(err) {
+			utils.Error(c, 400, err.Error())
+		} else {
+			utils.Error(c, 500, "更新作品文件失败")
+		}
+		return
+	}
+
+	h.imageURLService.SignArtwork(artwork)
+
+	utils.Success(c, gin.H{
+		"id":              artwork.ID,
+		"activity_id":     artwork.ActivityID,
+		"page":            page,
+		"file_name":       artwork.FileName,
+		"revision":        artwork.Revision,
+		"review_status":   artwork.ReviewStatus,
+		"image_urls":      artwork.ImageURLs,
+		"pages":           artwork.Pages,
+		"file_updated_at": artwork.FileUpdatedAt,
+	})
+}
+
+// AddArtworkPages appends one or more pages to a multi-page artwork
+// The files are sent in repeated "file" fields of a multipart form, in reading order
+// POST /api/v1/artworks/:id/pages
+func (h *ArtworkHandler) AddArtworkPages(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	// Get and validate uploaded files (size, type, and content)
+	files, err := openUploadedFiles(c, h.maxUploadSize)
+	if err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+	defer closeUploadedFiles(files)
+
+	artwork, err := h.artworkService.AddArtworkPages(uint(artworkID), userID.(uint), files)
+	if err != nil {
+		if strings.Contains(err.Error(), "权限") {
+			utils.Error(c, 403, err.Error())
+		} else if strings.Contains(err.Error(), "作品不存在") {
+			utils.Error(c, 404, err.Error())
+		} else if strings.Contains(err.Error(), "活动") || strings.Contains(err.Error(), "页数") || isImageValidationError(err) {
+			utils.Error(c, 400, err.Error())
+		} else {
+			utils.Error(c, 500, "添加作品页面失败")
+		}
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"id":            artwork.ID,
+		"page_count":    artwork.PageCount,
+		"review_status": artwork.ReviewStatus,
+	})
+}
+
+// ReorderPagesRequest represents the request body for reordering the pages of an artwork
+type ReorderPagesRequest struct {
+	Order []int `json:"order" binding:"required"`
+}
+
+// ReorderArtworkPages changes the reading order of a multi-page artwork's pages
+// PUT /api/v1/artworks/:id/pages/order
+func (h *ArtworkHandler) ReorderArtworkPages(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	var req ReorderPagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, 400, "参数错误")
+		return
+	}
+
+	artwork, err := h.artworkService.ReorderArtworkPages(uint(artworkID), userID.(uint), req.Order)
+	if err != nil {
+		if strings.Contains(err.Error(), "权限") {
+			utils.Error(c, 403, err.Error())
+		} else if strings.Contains(err.Error(), "作品不存在") {
+			utils.Error(c, 404, err.Error())
+		} else if strings.Contains(err.Error(), "已变化") {
+			utils.Error(c, 409, err.Error())
+		} else if strings.Contains(err.Error(), "活动") || strings.Contains(err.Error(), "页面顺序") {
+			utils.Error(c, 400, err.Error())
+		} else {
+			utils.Error(c, 500, "调整页面顺序失败")
+		}
+		return
+	}
+
+	h.imageURLService.SignArtwork(artwork)
+	utils.Success(c, artwork)
+}
+
+// SubmitArtwork submits one of the current user's drafts for review
+// POST /api/v1/artworks/:id/submit
+func (h *ArtworkHandler) SubmitArtwork(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	artwork, err := h.artworkService.SubmitArtwork(uint(artworkID), userID.(uint))
+	if err != nil {
+		if strings.Contains(err.Error(), "权限") {
+			utils.Error(c, 403, err.Error())
+		} else if strings.Contains(err.Error(), "作品不存在") {
+			utils.Error(c, 404, err.Error())
+		} else if strings.Contains(err.Error(), "不是草稿") {
+			utils.Error(c, 409, err.Error())
+		} else if strings.Contains(err.Error(), "活动") || strings.Contains(err.Error(), "上传数量") {
+			utils.Error(c, 400, err.Error())
+		} else {
+			utils.Error(c, 500, "提交作品失败")
+		}
+		return
+	}
+
+	h.imageURLService.SignArtwork(artwork)
+	utils.Success(c, artwork)
+}
+
+// ServePageImage serves one page of a multi-page artwork with permission check
+// Page 1 is the same image as GET /api/v1/artworks/:id/image
+// GET /api/v1/artworks/:id/pages/:page/image?size=thumb|preview|original
+func (h *ArtworkHandler) ServePageImage(c *gin.Context) {
+	// Get requester info from context
+	requesterID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	requesterRole, exists := c.Get("user_role")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID and page number from URL parameters
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	page, err := strconv.Atoi(c.Param("page"))
+	if err != nil || page <= 0 {
+		utils.Error(c, 400, "无效的页码")
+		return
+	}
+
+	size := c.DefaultQuery("size", service.RenditionOriginal)
+	if !service.IsValidRendition(size) {
+		utils.Error(c, 400, "无效的图片尺寸，可选值为 thumb、preview、original")
+		return
+	}
+
+	// Pages are only visible to those who may view the artwork itself
+	artworkInterface, err := h.artworkService.GetArtwork(uint(artworkID), requesterID.(uint), requesterRole.(string))
 	if err != nil {
 		if strings.Contains(err.Error(), "权限") || strings.Contains(err.Error(), "permission") {
 			utils.Error(c, 403, err.Error())
+		} else if strings.Contains(err.Error(), "不存在") || strings.Contains(err.Error(), "not found") {
+			utils.Error(c, 404, err.Error())
 		} else {
-			utils.Error(c, 500, "读取文件失败")
+			utils.Error(c, 500, "获取作品失败")
+		}
+		return
+	}
+
+	artwork, ok := artworkInterface.(*models.Artwork)
+	if !ok {
+		utils.Error(c, 500, "内部错误")
+		return
+	}
+
+	filePath, err := h.artworkService.GetArtworkPageFilePath(artwork, page)
+	if err != nil {
+		utils.Error(c, 404, err.Error())
+		return
+	}
+
+	serveStoredFile(c, h.fileService, h.fileService.ResolveRendition(filePath, size), privateImageCacheControl)
+}
+
+// GetArtworkRevisions retrieves the file revision history of an artwork
+// GET /api/v1/admin/artworks/:id/revisions
+func (h *ArtworkHandler) GetArtworkRevisions(c *gin.Context) {
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	artwork, revisions, err := h.artworkService.GetRevisions(uint(artworkID))
+	if err != nil {
+		if strings.Contains(err.Error(), "不存在") {
+			utils.Error(c, 404, err.Error())
+		} else {
+			utils.Error(c, 500, "获取作品版本失败")
+		}
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"current_revision": artwork.Revision,
+		"current_file":     artwork.FileName,
+		"revisions":        revisions,
+	})
+}
+
+// ServeRevisionImage serves the file of a previous artwork revision
+// GET /api/v1/admin/artworks/:id/revisions/:revision/image
+func (h *ArtworkHandler) ServeRevisionImage(c *gin.Context) {
+	// Get requester info from context
+	requesterID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	requesterRole, exists := c.Get("user_role")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID and revision number from URL parameters
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	revisionStr := c.Param("revision")
+	revisionNumber, err := strconv.Atoi(revisionStr)
+	if err != nil || revisionNumber <= 0 {
+		utils.Error(c, 400, "无效的版本号")
+		return
+	}
+
+	revision, err := h.artworkService.GetRevision(uint(artworkID), revisionNumber)
+	if err != nil {
+		utils.Error(c, 404, err.Error())
+		return
+	}
+
+	// Revisions are only visible to those who may view the artwork itself
+	if _, err := h.artworkService.GetArtwork(uint(artworkID), requesterID.(uint), requesterRole.(string)); err != nil {
+		if strings.Contains(err.Error(), "权限") {
+			utils.Error(c, 403, err.Error())
+		} else {
+			utils.Error(c, 404, err.Error())
 		}
 		return
 	}
 
-	// Set content type and return file
-	c.Data(200, contentType, fileData)
+	serveStoredFile(c, h.fileService, revision.FilePath, privateImageCacheControl)
 }
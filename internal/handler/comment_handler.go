@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"art-collection-system/internal/service"
+	"art-collection-system/internal/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CommentHandler handles artwork comment HTTP requests
+type CommentHandler struct {
+	commentService *service.CommentService
+}
+
+// NewCommentHandler creates a new comment handler instance
+func NewCommentHandler(commentService *service.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+// respondCommentError maps comment service errors to HTTP responses
+func respondCommentError(c *gin.Context, err error, fallback string) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "评论内容"):
+		utils.Error(c, 400, message)
+	case strings.Contains(message, "权限"):
+		utils.Error(c, 403, message)
+	case strings.Contains(message, "不存在"):
+		utils.Error(c, 404, message)
+	case strings.Contains(message, "已有回复"):
+		utils.Error(c, 409, message)
+	default:
+		utils.Error(c, 500, fallback)
+	}
+}
+
+// parseCommentPagination parses the page and page_size query parameters
+func parseCommentPagination(c *gin.Context) (int, int) {
+	page := 1
+	pageSize := 20
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= 100 {
+			pageSize = ps
+		}
+	}
+
+	return page, pageSize
+}
+
+// GetComments retrieves the comment threads on an artwork with pagination
+// GET /api/v1/artworks/:id/comments
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get user role from context
+	userRole, exists := c.Get("user_role")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID from URL parameter
+	artworkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	page, pageSize := parseCommentPagination(c)
+
+	comments, total, err := h.commentService.GetComments(uint(artworkID), userID.(uint), userRole.(string), page, pageSize)
+	if err != nil {
+		respondCommentError(c, err, "获取评论失败")
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"comments":  comments,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetGalleryComments retrieves the comment threads on an artwork in an activity's public gallery
+// GET /api/v1/activities/:id/gallery/:artworkId/comments
+func (h *CommentHandler) GetGalleryComments(c *gin.Context) {
+	activityID, artworkID, ok := parseGalleryIDs(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := parseCommentPagination(c)
+
+	comments, total, err := h.commentService.GetGalleryComments(activityID, artworkID, page, pageSize)
+	if err != nil {
+		respondGalleryError(c, err, "获取评论失败")
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"comments":  comments,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// AddCommentRequest represents the request body for commenting on an artwork
+type AddCommentRequest struct {
+	Content  string `json:"content" binding:"required"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// AddComment comments on an artwork or replies to one of its comments
+// POST /api/v1/artworks/:id/comments
+func (h *CommentHandler) AddComment(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get user role from context
+	userRole, exists := c.Get("user_role")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID from URL parameter
+	artworkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	var req AddCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, 400, "参数错误")
+		return
+	}
+
+	comment, err := h.commentService.AddComment(uint(artworkID), userID.(uint), userRole.(string), req.ParentID, req.Content)
+	if err != nil {
+		respondCommentError(c, err, "发表评论失败")
+		return
+	}
+
+	utils.Success(c, comment)
+}
+
+// DeleteComment permanently deletes a comment (own comments without replies, or any comment and its replies for admins)
+// DELETE /api/v1/comments/:id
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get user role from context
+	userRole, exists := c.Get("user_role")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get comment ID from URL parameter
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的评论ID")
+		return
+	}
+
+	if err := h.commentService.DeleteComment(uint(commentID), userID.(uint), userRole.(string)); err != nil {
+		respondCommentError(c, err, "删除评论失败")
+		return
+	}
+
+	utils.Success(c, gin.H{"message": "删除成功"})
+}
+
+// ListComments retrieves comments on all artworks for moderation (admin only)
+// GET /api/v1/admin/comments
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	page, pageSize := parseCommentPagination(c)
+
+	// Optional filters
+	var hidden *bool
+	if hiddenStr := c.Query("hidden"); hiddenStr != "" {
+		value, err := strconv.ParseBool(hiddenStr)
+		if err != nil {
+			utils.Error(c, 400, "无效的隐藏状态")
+			return
+		}
+		hidden = &value
+	}
+
+	var artworkID uint64
+	if artworkIDStr := c.Query("artwork_id"); artworkIDStr != "" {
+		var err error
+		artworkID, err = strconv.ParseUint(artworkIDStr, 10, 32)
+		if err != nil {
+			utils.Error(c, 400, "无效的作品ID")
+			return
+		}
+	}
+
+	comments, total, err := h.commentService.ListComments(hidden, uint(artworkID), page, pageSize)
+	if err != nil {
+		utils.Error(c, 500, "获取评论失败")
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"comments":  comments,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// SetCommentVisibilityRequest represents the request body for hiding or showing a comment
+type SetCommentVisibilityRequest struct {
+	Hidden bool `json:"hidden"`
+}
+
+// SetCommentVisibility hides a comment from everyone but admins, or shows it again (admin only)
+// PUT /api/v1/admin/comments/:id/visibility
+func (h *CommentHandler) SetCommentVisibility(c *gin.Context) {
+	// Get admin ID from context
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get comment ID from URL parameter
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的评论ID")
+		return
+	}
+
+	var req SetCommentVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, 400, "参数错误")
+		return
+	}
+
+	if err := h.commentService.SetCommentHidden(uint(commentID), adminID.(uint), req.Hidden); err != nil {
+		respondCommentError(c, err, "更新评论状态失败")
+		return
+	}
+
+	message := "评论已恢复显示"
+	if req.Hidden {
+		message = "评论已隐藏"
+	}
+
+	utils.Success(c, gin.H{"message": message, "is_hidden": req.Hidden})
+}
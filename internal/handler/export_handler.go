@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/service"
+	"art-collection-system/internal/utils"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler handles bulk export HTTP requests for administrators
+type ExportHandler struct {
+	exportService   *service.ExportService
+	leaseService    *service.ReviewLeaseService
+	defaultLanguage string
+}
+
+// NewExportHandler creates a new export handler instance
+// defaultLanguage is the header language of tabular exports when the request does not choose one
+func NewExportHandler(exportService *service.ExportService, leaseService *service.ReviewLeaseService, defaultLanguage string) *ExportHandler {
+	return &ExportHandler{
+		exportService:   exportService,
+		leaseService:    leaseService,
+		defaultLanguage: defaultLanguage,
+	}
+}
+
+// respondExportError maps export service errors to HTTP responses
+func respondExportError(c *gin.Context, err error, fallback string) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "不存在"):
+		utils.Error(c, 404, message)
+	case strings.Contains(message, "无效"):
+		utils.Error(c, 400, message)
+	default:
+		utils.Error(c, 500, fallback)
+	}
+}
+
+// parseStatusFilter parses a comma-separated review status filter such as "approved,pending"
+func parseStatusFilter(value string) []models.ReviewStatus {
+	var statuses []models.ReviewStatus
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			statuses = append(statuses, models.ReviewStatus(part))
+		}
+	}
+	return statuses
+}
+
+// ExportActivityZip streams all artworks of an activity as a ZIP archive
+// GET /api/v1/admin/activities/:id/export.zip
+func (h *ExportHandler) ExportActivityZip(c *gin.Context) {
+	// Get activity ID from URL parameter
+	activityIDStr := c.Param("id")
+	activityID, err := strconv.ParseUint(activityIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	export, err := h.exportService.GetActivityExport(uint(activityID), parseStatusFilter(c.Query("status")))
+	if err != nil {
+		respondExportError(c, err, "导出作品失败")
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="activity_%d_artworks.zip"`, activityID))
+	c.Status(200)
+
+	// The archive is streamed, so errors after this point can only end the response early
+	if err := h.exportService.WriteZip(c.Request.Context(), c.Writer, export); err != nil {
+		log.Printf("export: activity %d: %v", activityID, err)
+	}
+}
+
+// parseTableOptions reads the format and header language of a tabular export from the query
+// It writes an error response and returns false when either is invalid
+func (h *ExportHandler) parseTableOptions(c *gin.Context) (string, string, bool) {
+	format := c.DefaultQuery("format", utils.TableFormatCSV)
+	if !utils.IsValidTableFormat(format) {
+		utils.Error(c, 400, "无效的导出格式")
+		return "", "", false
+	}
+
+	language := c.DefaultQuery("lang", h.defaultLanguage)
+	if !service.IsValidExportLanguage(language) {
+		utils.Error(c, 400, "无效的表头语言")
+		return "", "", false
+	}
+
+	return format, language, true
+}
+
+// writeTable streams a tabular export as a file download
+func (h *ExportHandler) writeTable(c *gin.Context, export *service.TableExport, format, language string) {
+	c.Header("Content-Type", utils.TableContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, export.Name, format))
+	c.Status(200)
+
+	// Rows are streamed, so errors after this point can only end the response early
+	if err := h.exportService.WriteTable(c.Writer, export, format, language); err != nil {
+		log.Printf("export: %s: %v", export.Name, err)
+	}
+}
+
+// ExportActivityArtworks exports the artworks of an activity as CSV or XLSX
+// GET /api/v1/admin/activities/:id/artworks/export
+func (h *ExportHandler) ExportActivityArtworks(c *gin.Context) {
+	// Get activity ID from URL parameter
+	activityIDStr := c.Param("id")
+	activityID, err := strconv.ParseUint(activityIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	format, language, ok := h.parseTableOptions(c)
+	if !ok {
+		return
+	}
+
+	// Same tag filter as the JSON listing
+	export, err := h.exportService.ActivityArtworksTable(uint(activityID), c.Query("tag"))
+	if err != nil {
+		respondExportError(c, err, "导出作品失败")
+		return
+	}
+
+	h.writeTable(c, export, format, language)
+}
+
+// ExportReviewQueue exports the artworks pending review as CSV or XLSX
+// Artworks claimed by other reviewers are left out, as in the review queue
+// GET /api/v1/admin/review-queue/export
+func (h *ExportHandler) ExportReviewQueue(c *gin.Context) {
+	// Get reviewer ID from context
+	reviewerID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	format, language, ok := h.parseTableOptions(c)
+	if !ok {
+		return
+	}
+
+	leasedIDs, err := h.leaseService.LeasedByOthers(reviewerID.(uint))
+	if err != nil {
+		utils.Error(c, 500, "导出审核队列失败")
+		return
+	}
+
+	h.writeTable(c, h.exportService.ReviewQueueTable(leasedIDs), format, language)
+}
+
+// ExportUsers exports all users as CSV or XLSX
+// GET /api/v1/admin/users/export
+func (h *ExportHandler) ExportUsers(c *gin.Context) {
+	format, language, ok := h.parseTableOptions(c)
+	if !ok {
+		return
+	}
+
+	h.writeTable(c, h.exportService.UsersTable(), format, language)
+}
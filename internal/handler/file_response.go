@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"art-collection-system/internal/service"
+	"art-collection-system/internal/storage"
+	"art-collection-system/internal/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache-Control values for served images
+const (
+	// privateImageCacheControl lets browsers keep a private copy but revalidate it on every use,
+	// so permission changes take effect immediately while unchanged images cost only a 304
+	privateImageCacheControl = "private, no-cache"
+
+	// publicImageCacheControl allows shared caches to keep gallery images for a short time
+	publicImageCacheControl = "public, max-age=300"
+)
+
+// serveStoredFile streams a stored file to the client
+// Range requests, If-None-Match, If-Modified-Since and If-Range are handled by http.ServeContent
+func serveStoredFile(c *gin.Context, fileService *service.FileService, filePath, cacheControl string) {
+	file, err := fileService.OpenFile(filePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			utils.Error(c, 404, "文件不存在")
+		} else {
+			utils.Error(c, 500, "读取文件失败")
+		}
+		return
+	}
+	defer file.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", file.ContentType)
+	header.Set("ETag", file.ETag)
+	header.Set("Cache-Control", cacheControl)
+
+	http.ServeContent(c.Writer, c.Request, "", file.ModTime, file)
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"art-collection-system/internal/service"
+	"art-collection-system/internal/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GalleryHandler handles public gallery HTTP requests
+type GalleryHandler struct {
+	galleryService *service.GalleryService
+	fileService    *service.FileService
+}
+
+// NewGalleryHandler creates a new gallery handler instance
+func NewGalleryHandler(galleryService *service.GalleryService, fileService *service.FileService) *GalleryHandler {
+	return &GalleryHandler{
+		galleryService: galleryService,
+		fileService:    fileService,
+	}
+}
+
+// respondGalleryError maps gallery service errors to HTTP responses
+// A gallery that is not open yet is reported as not found so that its existence is not leaked
+func respondGalleryError(c *gin.Context, err error, fallback string) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "不存在") || strings.Contains(message, "未开放"):
+		utils.Error(c, 404, message)
+	default:
+		utils.Error(c, 500, fallback)
+	}
+}
+
+// parseGalleryIDs parses the activity ID and artwork ID URL parameters
+func parseGalleryIDs(c *gin.Context) (uint, uint, bool) {
+	activityID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return 0, 0, false
+	}
+
+	artworkID, err := strconv.ParseUint(c.Param("artworkId"), 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return 0, 0, false
+	}
+
+	return uint(activityID), uint(artworkID), true
+}
+
+// ListGallery retrieves the approved artworks of an activity's public gallery with pagination
+// GET /api/v1/activities/:id/gallery
+func (h *GalleryHandler) ListGallery(c *gin.Context) {
+	// Get activity ID from URL parameter
+	activityIDStr := c.Param("id")
+	activityID, err := strconv.ParseUint(activityIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	// Parse pagination parameters
+	page := 1
+	pageSize := 20
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= 100 {
+			pageSize = ps
+		}
+	}
+
+	artworks, total, err := h.galleryService.ListGallery(uint(activityID), page, pageSize)
+	if err != nil {
+		respondGalleryError(c, err, "获取作品展示失败")
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"artworks":  artworks,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetGalleryArtwork retrieves a single artwork from an activity's public gallery
+// GET /api/v1/activities/:id/gallery/:artworkId
+func (h *GalleryHandler) GetGalleryArtwork(c *gin.Context) {
+	activityID, artworkID, ok := parseGalleryIDs(c)
+	if !ok {
+		return
+	}
+
+	artwork, err := h.galleryService.GetGalleryArtwork(activityID, artworkID)
+	if err != nil {
+		respondGalleryError(c, err, "获取作品失败")
+		return
+	}
+
+	utils.Success(c, artwork)
+}
+
+// ServeGalleryImage serves the image of an artwork in an activity's public gallery
+// GET /api/v1/activities/:id/gallery/:artworkId/image?size=thumb|preview|original
+func (h *GalleryHandler) ServeGalleryImage(c *gin.Context) {
+	activityID, artworkID, ok := parseGalleryIDs(c)
+	if !ok {
+		return
+	}
+
+	// Get requested rendition, defaulting to the original
+	size := c.DefaultQuery("size", service.RenditionOriginal)
+	if !service.IsValidRendition(size) {
+		utils.Error(c, 400, "无效的图片尺寸，可选值为 thumb、preview、original")
+		return
+	}
+
+	h.serveGalleryImage(c, activityID, artworkID, 1, size)
+}
+
+// ServeGalleryPageImage serves one page of a multi-page artwork in an activity's public gallery
+// GET /api/v1/activities/:id/gallery/:artworkId/pages/:page/image?size=thumb|preview|original
+func (h *GalleryHandler) ServeGalleryPageImage(c *gin.Context) {
+	activityID, artworkID, ok := parseGalleryIDs(c)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(c.Param("page"))
+	if err != nil || page <= 0 {
+		utils.Error(c, 400, "无效的页码")
+		return
+	}
+
+	// Get requested rendition, defaulting to the original
+	size := c.DefaultQuery("size", service.RenditionOriginal)
+	if !service.IsValidRendition(size) {
+		utils.Error(c, 400, "无效的图片尺寸，可选值为 thumb、preview、original")
+		return
+	}
+
+	h.serveGalleryImage(c, activityID, artworkID, page, size)
+}
+
+// serveGalleryImage streams a rendition of a page of a gallery artwork
+func (h *GalleryHandler) serveGalleryImage(c *gin.Context, activityID, artworkID uint, page int, size string) {
+	filePath, err := h.galleryService.GetGalleryImagePath(activityID, artworkID, page)
+	if err != nil {
+		respondGalleryError(c, err, "获取作品失败")
+		return
+	}
+
+	serveStoredFile(c, h.fileService, h.fileService.ResolveRendition(filePath, size), publicImageCacheControl)
+}
@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"art-collection-system/internal/service"
+	"art-collection-system/internal/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScoringHandler handles rubric scoring HTTP requests for administrators and judges
+type ScoringHandler struct {
+	scoringService  *service.ScoringService
+	imageURLService *service.ImageURLService
+}
+
+// NewScoringHandler creates a new scoring handler instance
+func NewScoringHandler(scoringService *service.ScoringService, imageURLService *service.ImageURLService) *ScoringHandler {
+	return &ScoringHandler{
+		scoringService:  scoringService,
+		imageURLService: imageURLService,
+	}
+}
+
+// respondScoringError maps scoring service errors to HTTP responses
+func respondScoringError(c *gin.Context, err error, fallback string) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "权限"):
+		utils.Error(c, 403, message)
+	case strings.Contains(message, "不存在"):
+		utils.Error(c, 404, message)
+	case strings.Contains(message, "评分") || strings.Contains(message, "无效") || strings.Contains(message, "只能"):
+		utils.Error(c, 400, message)
+	default:
+		utils.Error(c, 500, fallback)
+	}
+}
+
+// GetRubric retrieves the scoring rubric of an activity
+// GET /api/v1/admin/activities/:id/rubric
+func (h *ScoringHandler) GetRubric(c *gin.Context) {
+	// Get activity ID from URL parameter
+	activityIDStr := c.Param("id")
+	activityID, err := strconv.ParseUint(activityIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	criteria, err := h.scoringService.GetRubric(uint(activityID))
+	if err != nil {
+		respondScoringError(c, err, "获取评分标准失败")
+		return
+	}
+
+	utils.Success(c, gin.H{"criteria": criteria})
+}
+
+// RubricCriterionRequest represents a single criterion in the rubric request body
+type RubricCriterionRequest struct {
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	Weight      float64 `json:"weight" binding:"required"`
+	MinScore    float64 `json:"min_score"`
+	MaxScore    float64 `json:"max_score" binding:"required"`
+}
+
+// SetRubricRequest represents the request body for setting an activity's rubric
+type SetRubricRequest struct {
+	Criteria []RubricCriterionRequest `json:"criteria" binding:"required,dive"`
+}
+
+// SetRubric replaces the scoring rubric of an activity
+// PUT /api/v1/admin/activities/:id/rubric
+func (h *ScoringHandler) SetRubric(c *gin.Context) {
+	// Get activity ID from URL parameter
+	activityIDStr := c.Param("id")
+	activityID, err := strconv.ParseUint(activityIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	var req SetRubricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, 400, "参数错误")
+		return
+	}
+
+	inputs := make([]service.CriterionInput, 0, len(req.Criteria))
+	for _, criterion := range req.Criteria {
+		inputs = append(inputs, service.CriterionInput{
+			Name:        criterion.Name,
+			Description: criterion.Description,
+			Weight:      criterion.Weight,
+			MinScore:    criterion.MinScore,
+			MaxScore:    criterion.MaxScore,
+		})
+	}
+
+	criteria, err := h.scoringService.SetRubric(uint(activityID), inputs)
+	if err != nil {
+		respondScoringError(c, err, "设置评分标准失败")
+		return
+	}
+
+	utils.Success(c, gin.H{"criteria": criteria})
+}
+
+// GetJudges retrieves the judges assigned to an activity
+// GET /api/v1/admin/activities/:id/judges
+func (h *ScoringHandler) GetJudges(c *gin.Context) {
+	// Get activity ID from URL parameter
+	activityIDStr := c.Param("id")
+	activityID, err := strconv.ParseUint(activityIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	judges, err := h.scoringService.GetJudges(uint(activityID))
+	if err != nil {
+		respondScoringError(c, err, "获取评委列表失败")
+		return
+	}
+
+	utils.Success(c, gin.H{"judges": judges})
+}
+
+// SetJudgesRequest represents the request body for assigning judges to an activity
+type SetJudgesRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"required"`
+}
+
+// SetJudges replaces the judges assigned to an activity
+// PUT /api/v1/admin/activities/:id/judges
+func (h *ScoringHandler) SetJudges(c *gin.Context) {
+	// Get activity ID from URL parameter
+	activityIDStr := c.Param("id")
+	activityID, err := strconv.ParseUint(activityIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	var req SetJudgesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, 400, "参数错误")
+		return
+	}
+
+	if err := h.scoringService.SetJudges(uint(activityID), req.UserIDs); err != nil {
+		respondScoringError(c, err, "设置评委失败")
+		return
+	}
+
+	utils.Success(c, gin.H{"message": "设置评委成功"})
+}
+
+// SetScoringStatusRequest represents the request body for opening or closing scoring
+type SetScoringStatusRequest struct {
+	Closed bool `json:"closed"`
+}
+
+// SetScoringStatus opens or closes scoring for an activity
+// PUT /api/v1/admin/activities/:id/scoring
+func (h *ScoringHandler) SetScoringStatus(c *gin.Context) {
+	// Get activity ID from URL parameter
+	activityIDStr := c.Param("id")
+	activityID, err := strconv.ParseUint(activityIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	var req SetScoringStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, 400, "参数错误")
+		return
+	}
+
+	if err := h.scoringService.SetScoringClosed(uint(activityID), req.Closed); err != nil {
+		respondScoringError(c, err, "更新评分状态失败")
+		return
+	}
+
+	message := "评分已开放"
+	if req.Closed {
+		message = "评分已结束"
+	}
+
+	utils.Success(c, gin.H{"message": message, "scoring_closed": req.Closed})
+}
+
+// GetResults retrieves the ranked scoring results of an activity
+// GET /api/v1/admin/activities/:id/results
+// GET /api/v1/judging/activities/:id/results
+func (h *ScoringHandler) GetResults(c *gin.Context) {
+	// Get requester info from context
+	requesterID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	requesterRole, exists := c.Get("user_role")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get activity ID from URL parameter
+	activityIDStr := c.Param("id")
+	activityID, err := strconv.ParseUint(activityIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	results, err := h.scoringService.GetResults(uint(activityID), requesterID.(uint), requesterRole.(string))
+	if err != nil {
+		respondScoringError(c, err, "获取评分结果失败")
+		return
+	}
+
+	h.imageURLService.SignScoringResults(results)
+
+	utils.Success(c, gin.H{
+		"results": results,
+		"total":   len(results),
+	})
+}
+
+// GetJudgedActivities retrieves the activities the current user is assigned to judge
+// GET /api/v1/judging/activities
+func (h *ScoringHandler) GetJudgedActivities(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	activities, err := h.scoringService.GetJudgedActivities(userID.(uint))
+	if err != nil {
+		utils.Error(c, 500, "获取评审活动失败")
+		return
+	}
+
+	utils.Success(c, gin.H{"activities": activities})
+}
+
+// GetJudgingSheet retrieves the rubric, approved artworks and the current judge's own scores
+// GET /api/v1/judging/activities/:id/artworks
+func (h *ScoringHandler) GetJudgingSheet(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get activity ID from URL parameter
+	activityIDStr := c.Param("id")
+	activityID, err := strconv.ParseUint(activityIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	criteria, entries, err := h.scoringService.GetJudgingSheet(uint(activityID), userID.(uint))
+	if err != nil {
+		respondScoringError(c, err, "获取评审作品失败")
+		return
+	}
+
+	// Judges are not the artworks' authors, so they view the images through signed URLs
+	h.imageURLService.SignJudgingEntries(entries)
+
+	utils.Success(c, gin.H{
+		"criteria": criteria,
+		"artworks": entries,
+		"total":    len(entries),
+	})
+}
+
+// ScoreRequest represents a single criterion score in the submit scores request body
+type ScoreRequest struct {
+	CriterionID uint    `json:"criterion_id" binding:"required"`
+	Score       float64 `json:"score"`
+}
+
+// SubmitScoresRequest represents the request body for scoring an artwork
+type SubmitScoresRequest struct {
+	Scores []ScoreRequest `json:"scores" binding:"required,dive"`
+}
+
+// SubmitScores records the current judge's scores for an artwork
+// PUT /api/v1/judging/artworks/:id/scores
+func (h *ScoringHandler) SubmitScores(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID from URL parameter
+	artworkIDStr := c.Param("id")
+	artworkID, err := strconv.ParseUint(artworkIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	var req SubmitScoresRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, 400, "参数错误")
+		return
+	}
+
+	inputs := make([]service.ScoreInput, 0, len(req.Scores))
+	for _, score := range req.Scores {
+		inputs = append(inputs, service.ScoreInput{
+			CriterionID: score.CriterionID,
+			Score:       score.Score,
+		})
+	}
+
+	if err := h.scoringService.SubmitScores(userID.(uint), uint(artworkID), inputs); err != nil {
+		respondScoringError(c, err, "提交评分失败")
+		return
+	}
+
+	utils.Success(c, gin.H{"message": "评分成功"})
+}
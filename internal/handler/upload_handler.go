@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"art-collection-system/internal/service"
+	"art-collection-system/internal/utils"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tusVersion is the version of the tus resumable upload protocol implemented
+const tusVersion = "1.0.0"
+
+// tusExtensions lists the tus protocol extensions supported
+const tusExtensions = "creation,expiration,termination"
+
+// UploadHandler handles resumable artwork uploads over the tus protocol
+// See https://tus.io/protocols/resumable-upload
+type UploadHandler struct {
+	uploadService   *service.ResumableUploadService
+	imageURLService *service.ImageURLService
+}
+
+// NewUploadHandler creates a new upload handler instance
+func NewUploadHandler(uploadService *service.ResumableUploadService, imageURLService *service.ImageURLService) *UploadHandler {
+	return &UploadHandler{
+		uploadService:   uploadService,
+		imageURLService: imageURLService,
+	}
+}
+
+// requireTusResumable sets the protocol version header and checks that the client speaks it
+func requireTusResumable(c *gin.Context) bool {
+	c.Header("Tus-Resumable", tusVersion)
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		utils.Error(c, http.StatusPreconditionFailed, "不支持的 tus 协议版本")
+		return false
+	}
+	return true
+}
+
+// setUploadHeaders sets the headers describing the state of an upload
+func setUploadHeaders(c *gin.Context, upload *service.ResumableUpload) {
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "no-store")
+}
+
+// respondResumableError maps resumable upload errors to HTTP responses
+func respondResumableError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUploadNotFound):
+		utils.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrUploadOffsetMismatch):
+		utils.Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrUploadLocked):
+		utils.Error(c, http.StatusLocked, err.Error())
+	case errors.Is(err, service.ErrUploadLengthExceeded), errors.Is(err, utils.ErrFileTooLarge):
+		utils.Error(c, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		respondUploadError(c, err)
+	}
+}
+
+// parseUploadMetadata decodes a tus Upload-Metadata header
+// The header is a comma-separated list of "key base64(value)" pairs; the value may be omitted
+func parseUploadMetadata(header string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, errors.New("无效的上传元数据")
+		}
+		values[key] = string(value)
+	}
+	return values, nil
+}
+
+// GetUploadOptions describes the supported tus protocol version, extensions and maximum size
+// OPTIONS /api/v1/uploads
+func (h *UploadHandler) GetUploadOptions(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Header("Tus-Max-Size", strconv.FormatInt(h.uploadService.MaxSize(), 10))
+	c.Status(http.StatusNoContent)
+}
+
+// CreateUpload starts a resumable artwork upload
+// The activity, file name, optional artwork metadata and draft flag are sent in the Upload-Metadata header
+// POST /api/v1/uploads
+func (h *UploadHandler) CreateUpload(c *gin.Context) {
+	if !requireTusResumable(c) {
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		utils.Error(c, 400, "上传时必须声明文件大小")
+		return
+	}
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		utils.Error(c, 400, "无效的文件大小")
+		return
+	}
+
+	values, err := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	activityIDStr := values["activity_id"]
+	if activityIDStr == "" {
+		utils.Error(c, 400, "活动ID不能为空")
+		return
+	}
+	activityID, err := strconv.ParseUint(activityIDStr, 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	filename := values["filename"]
+	if filename == "" {
+		utils.Error(c, 400, "文件名不能为空")
+		return
+	}
+
+	// Optional descriptive metadata, with the same fields as the multipart upload form
+	metadata, err := parseMetadataFields(func(key string) string { return values[key] }, []string{values["tags"]})
+	if err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	draft, err := parseDraftFlag(values["draft"])
+	if err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	upload, err := h.uploadService.Create(userID.(uint), uint(activityID), filename, length, metadata, draft)
+	if err != nil {
+		respondResumableError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/uploads/"+upload.ID)
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusCreated)
+}
+
+// GetUploadOffset reports how much of a resumable upload has been received
+// HEAD /api/v1/uploads/:id
+func (h *UploadHandler) GetUploadOffset(c *gin.Context) {
+	if !requireTusResumable(c) {
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	upload, err := h.uploadService.Get(userID.(uint), c.Param("id"))
+	if err != nil {
+		respondResumableError(c, err)
+		return
+	}
+
+	setUploadHeaders(c, upload)
+	c.Status(http.StatusOK)
+}
+
+// PatchUpload appends a chunk to a resumable upload
+// The chunk completing the upload creates the artwork and returns it like POST /api/v1/artworks;
+// a PATCH with an empty body to a fully received upload retries creating the artwork
+// PATCH /api/v1/uploads/:id
+func (h *UploadHandler) PatchUpload(c *gin.Context) {
+	if !requireTusResumable(c) {
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	if c.ContentType() != "application/offset+octet-stream" {
+		utils.Error(c, http.StatusUnsupportedMediaType, "Content-Type 必须为 application/offset+octet-stream")
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		utils.Error(c, 400, "无效的上传偏移量")
+		return
+	}
+
+	upload, err := h.uploadService.WriteChunk(userID.(uint), c.Param("id"), offset, c.Request.Body)
+	if upload != nil {
+		setUploadHeaders(c, upload)
+	}
+	if err != nil {
+		respondResumableError(c, err)
+		return
+	}
+
+	if upload.Offset < upload.Length {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	// All data received: assemble the file and create the artwork
+	artwork, err := h.uploadService.Complete(userID.(uint), upload.ID)
+	if err != nil {
+		respondResumableError(c, err)
+		return
+	}
+
+	h.imageURLService.SignArtwork(artwork)
+
+	utils.Success(c, uploadedArtworkResponse(artwork))
+}
+
+// DeleteUpload cancels a resumable upload and discards the data received
+// DELETE /api/v1/uploads/:id
+func (h *UploadHandler) DeleteUpload(c *gin.Context) {
+	if !requireTusResumable(c) {
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	if err := h.uploadService.Delete(userID.(uint), c.Param("id")); err != nil {
+		respondResumableError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -11,13 +11,15 @@ import (
 
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
-	userService *service.UserService
+	userService     *service.UserService
+	imageURLService *service.ImageURLService
 }
 
 // NewUserHandler creates a new user handler instance
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, imageURLService *service.ImageURLService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:     userService,
+		imageURLService: imageURLService,
 	}
 }
 
@@ -162,6 +164,8 @@ func (h *UserHandler) GetUserArtworks(c *gin.Context) {
 		return
 	}
 
+	h.imageURLService.SignArtworks(artworks)
+
 	utils.Success(c, gin.H{
 		"artworks": artworks,
 		"total":    len(artworks),
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"art-collection-system/internal/service"
+	"art-collection-system/internal/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VoteHandler handles public voting HTTP requests
+type VoteHandler struct {
+	voteService *service.VoteService
+}
+
+// NewVoteHandler creates a new vote handler instance
+func NewVoteHandler(voteService *service.VoteService) *VoteHandler {
+	return &VoteHandler{
+		voteService: voteService,
+	}
+}
+
+// respondVoteError maps vote service errors to HTTP responses
+func respondVoteError(c *gin.Context, err error, fallback string) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "不存在"):
+		utils.Error(c, 404, message)
+	case strings.Contains(message, "自己的作品"):
+		utils.Error(c, 403, message)
+	case strings.Contains(message, "投过票") || strings.Contains(message, "尚未"):
+		utils.Error(c, 409, message)
+	case strings.Contains(message, "投票时间") || strings.Contains(message, "最多投"):
+		utils.Error(c, 400, message)
+	default:
+		utils.Error(c, 500, fallback)
+	}
+}
+
+// Vote casts the current user's vote for an approved artwork
+// POST /api/v1/artworks/:id/vote
+func (h *VoteHandler) Vote(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID from URL parameter
+	artworkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	count, err := h.voteService.Vote(userID.(uint), uint(artworkID))
+	if err != nil {
+		respondVoteError(c, err, "投票失败")
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"artwork_id": artworkID,
+		"vote_count": count,
+	})
+}
+
+// Unvote withdraws the current user's vote for an artwork
+// DELETE /api/v1/artworks/:id/vote
+func (h *VoteHandler) Unvote(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get artwork ID from URL parameter
+	artworkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的作品ID")
+		return
+	}
+
+	count, err := h.voteService.Unvote(userID.(uint), uint(artworkID))
+	if err != nil {
+		respondVoteError(c, err, "取消投票失败")
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"artwork_id": artworkID,
+		"vote_count": count,
+	})
+}
+
+// GetMyVotes retrieves the current user's votes in an activity and how many remain
+// GET /api/v1/activities/:id/votes/mine
+func (h *VoteHandler) GetMyVotes(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, 401, "未授权")
+		return
+	}
+
+	// Get activity ID from URL parameter
+	activityID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, 400, "无效的活动ID")
+		return
+	}
+
+	status, err := h.voteService.GetUserVotes(userID.(uint), uint(activityID))
+	if err != nil {
+		respondVoteError(c, err, "获取投票记录失败")
+		return
+	}
+
+	utils.Success(c, status)
+}
@@ -139,3 +139,37 @@ func UploadRateLimiter(redis *redis.Client) gin.HandlerFunc {
 		return fmt.Sprintf("%v", userID)
 	})
 }
+
+// VoteRateLimiter 投票速率限制（每个用户每分钟 30 次）
+func VoteRateLimiter(redis *redis.Client) gin.HandlerFunc {
+	limiter := NewRateLimiter(redis, RateLimitConfig{
+		MaxRequests: 30,
+		Window:      time.Minute,
+		KeyPrefix:   "rate_limit:vote:",
+	})
+
+	return limiter.Middleware(func(c *gin.Context) string {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			return ""
+		}
+		return fmt.Sprintf("%v", userID)
+	})
+}
+
+// CommentRateLimiter 评论速率限制（每个用户每分钟 5 次）
+func CommentRateLimiter(redis *redis.Client) gin.HandlerFunc {
+	limiter := NewRateLimiter(redis, RateLimitConfig{
+		MaxRequests: 5,
+		Window:      time.Minute,
+		KeyPrefix:   "rate_limit:comment:",
+	})
+
+	return limiter.Middleware(func(c *gin.Context) string {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			return ""
+		}
+		return fmt.Sprintf("%v", userID)
+	})
+}
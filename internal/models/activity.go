@@ -11,13 +11,66 @@ type Activity struct {
 	Deadline          *time.Time `json:"deadline"`
 	Description       string     `gorm:"type:text" json:"description"`
 	MaxUploadsPerUser int        `gorm:"default:5;not null" json:"max_uploads_per_user"`
+	ScoringClosed     bool       `gorm:"default:false;not null" json:"scoring_closed"`
+	GalleryEnabled    bool       `gorm:"default:false;not null" json:"gallery_enabled"`
+	GalleryOpensAt    *time.Time `json:"gallery_opens_at"`
+	VotingEnabled     bool       `gorm:"default:false;not null" json:"voting_enabled"`
+	VotingOpensAt     *time.Time `json:"voting_opens_at"`
+	VotingClosesAt    *time.Time `json:"voting_closes_at"`
+	MaxVotesPerUser   int        `gorm:"default:0;not null" json:"max_votes_per_user"` // 0 allows a vote for every artwork
 	IsDeleted         bool       `gorm:"default:false;not null;index" json:"-"`
 	CreatedAt         time.Time  `json:"created_at"`
 	UpdatedAt         time.Time  `json:"updated_at"`
 
+	ImageConstraints ImageConstraints `gorm:"embedded;embeddedPrefix:image_" json:"image_constraints"`
+
 	Artworks []Artwork `gorm:"foreignKey:ActivityID" json:"artworks,omitempty"`
 }
 
+// ImageFormats lists the image formats an activity can accept, as reported by image.DecodeConfig
+var ImageFormats = []string{"jpeg", "png", "gif", "webp", "bmp"}
+
+// ImageConstraints restricts the images accepted by an activity; zero values mean no restriction
+// Aspect ratios are width divided by height, so portrait images have a ratio below 1
+type ImageConstraints struct {
+	AllowedFormats []string `gorm:"type:json;serializer:json" json:"allowed_formats"`
+	MinBytes       int64    `gorm:"not null;default:0" json:"min_bytes"`
+	MaxBytes       int64    `gorm:"not null;default:0" json:"max_bytes"`
+	MinWidth       int      `gorm:"not null;default:0" json:"min_width"`
+	MaxWidth       int      `gorm:"not null;default:0" json:"max_width"`
+	MinHeight      int      `gorm:"not null;default:0" json:"min_height"`
+	MaxHeight      int      `gorm:"not null;default:0" json:"max_height"`
+	MinAspectRatio float64  `gorm:"not null;default:0" json:"min_aspect_ratio"`
+	MaxAspectRatio float64  `gorm:"not null;default:0" json:"max_aspect_ratio"`
+}
+
+// NeedsDimensions reports whether checking the constraints requires decoding the image header
+func (c *ImageConstraints) NeedsDimensions() bool {
+	return len(c.AllowedFormats) > 0 ||
+		c.MinWidth > 0 || c.MaxWidth > 0 ||
+		c.MinHeight > 0 || c.MaxHeight > 0 ||
+		c.MinAspectRatio > 0 || c.MaxAspectRatio > 0
+}
+
+// IsGalleryOpen reports whether the activity's public gallery is visible at the given time
+func (a *Activity) IsGalleryOpen(now time.Time) bool {
+	if !a.GalleryEnabled {
+		return false
+	}
+	return a.GalleryOpensAt == nil || !now.Before(*a.GalleryOpensAt)
+}
+
+// IsVotingOpen reports whether users can vote for the activity's artworks at the given time
+func (a *Activity) IsVotingOpen(now time.Time) bool {
+	if !a.VotingEnabled {
+		return false
+	}
+	if a.VotingOpensAt != nil && now.Before(*a.VotingOpensAt) {
+		return false
+	}
+	return a.VotingClosesAt == nil || now.Before(*a.VotingClosesAt)
+}
+
 // TableName specifies the table name for Activity model
 func (Activity) TableName() string {
 	return "activities"
@@ -10,21 +10,104 @@ type ReviewStatus string
 const (
 	StatusPending  ReviewStatus = "pending"
 	StatusApproved ReviewStatus = "approved"
+	StatusRejected ReviewStatus = "rejected"
+
+	// StatusDraft marks an artwork its author has not submitted yet; drafts are
+	// only visible to their author and do not enter the review queue
+	StatusDraft ReviewStatus = "draft"
+)
+
+// IsValid reports whether the status is one of the known review statuses
+// Drafts are not reviewed, so StatusDraft is not one of them
+func (s ReviewStatus) IsValid() bool {
+	switch s {
+	case StatusPending, StatusApproved, StatusRejected:
+		return true
+	}
+	return false
+}
+
+// RejectReasonCode is a predefined reason for rejecting an artwork
+type RejectReasonCode string
+
+const (
+	RejectOffTopic      RejectReasonCode = "off_topic"
+	RejectLowQuality    RejectReasonCode = "low_quality"
+	RejectInappropriate RejectReasonCode = "inappropriate"
+	RejectCopyright     RejectReasonCode = "copyright"
+	RejectFormat        RejectReasonCode = "format"
+	RejectOther         RejectReasonCode = "other"
 )
 
+// RejectReasonCodes lists the predefined reject reason codes in display order
+var RejectReasonCodes = []RejectReasonCode{
+	RejectOffTopic,
+	RejectLowQuality,
+	RejectInappropriate,
+	RejectCopyright,
+	RejectFormat,
+	RejectOther,
+}
+
+// RejectReasonLabels maps predefined reject reason codes to their display labels
+var RejectReasonLabels = map[RejectReasonCode]string{
+	RejectOffTopic:      "与活动主题不符",
+	RejectLowQuality:    "作品质量不符合要求",
+	RejectInappropriate: "包含不适宜内容",
+	RejectCopyright:     "涉嫌抄袭或侵权",
+	RejectFormat:        "图片格式或清晰度不符合要求",
+	RejectOther:         "其他",
+}
+
 // Artwork represents an artwork submitted by a user
 type Artwork struct {
-	ID           uint         `gorm:"primaryKey" json:"id"`
-	ActivityID   uint         `gorm:"not null;index:idx_activity_id" json:"activity_id"`
-	UserID       uint         `gorm:"not null;index:idx_user_id,priority:1;index:idx_user_activity,priority:1" json:"user_id"`
-	FilePath     string       `gorm:"not null;size:500" json:"-"`
-	FileName     string       `gorm:"not null;size:255" json:"file_name"`
-	ReviewStatus ReviewStatus `gorm:"type:enum('pending','approved');default:'pending';not null;index:idx_review_status" json:"review_status"`
-	CreatedAt    time.Time    `gorm:"index" json:"created_at"`
-	UpdatedAt    time.Time    `json:"updated_at"`
+	ID                uint               `gorm:"primaryKey" json:"id"`
+	ActivityID        uint               `gorm:"not null;index:idx_activity_id;index:idx_activity_content_hash,priority:1" json:"activity_id"`
+	UserID            uint               `gorm:"not null;index:idx_user_id,priority:1;index:idx_user_activity,priority:1" json:"user_id"`
+	BlobID            *uint              `gorm:"index:idx_artworks_blob_id" json:"-"`
+	FilePath          string             `gorm:"not null;size:500" json:"-"`
+	FileName          string             `gorm:"not null;size:255" json:"file_name"`
+	Title             string             `gorm:"not null;size:200;default:''" json:"title"`
+	Description       string             `gorm:"type:text" json:"description"`
+	Medium            string             `gorm:"size:100" json:"medium"`
+	WidthCM           *float64           `gorm:"column:width_cm" json:"width_cm"`
+	HeightCM          *float64           `gorm:"column:height_cm" json:"height_cm"`
+	DepthCM           *float64           `gorm:"column:depth_cm" json:"depth_cm"`
+	CreationYear      *int               `json:"creation_year"`
+	Tags              []string           `gorm:"type:json;serializer:json" json:"tags"`
+	PageCount         int                `gorm:"not null;default:1" json:"page_count"`
+	VoteCount         int64              `gorm:"not null;default:0" json:"vote_count"` // synced periodically from the live counters
+	Revision          int                `gorm:"not null;default:1" json:"revision"`
+	FileUpdatedAt     *time.Time         `json:"file_updated_at,omitempty"`
+	OriginalMetadata  map[string]string  `gorm:"type:json;serializer:json" json:"-"`
+	ContentHash       string             `gorm:"not null;size:64;default:'';index:idx_activity_content_hash,priority:2;index:idx_artworks_content_hash" json:"-"`
+	PerceptualHash    *uint64            `json:"-"`
+	DuplicateOfID     *uint              `json:"duplicate_of_id,omitempty"`
+	ReviewStatus      ReviewStatus       `gorm:"type:enum('draft','pending','approved','rejected');default:'pending';not null;index:idx_review_status" json:"review_status"`
+	RejectReason      string             `gorm:"type:text" json:"reject_reason,omitempty"`
+	RejectReasonCodes []RejectReasonCode `gorm:"type:json;serializer:json" json:"reject_reason_codes,omitempty"`
+	IsDeleted         bool               `gorm:"default:false;not null;index" json:"-"`
+	DeletedAt         *time.Time         `gorm:"index" json:"deleted_at,omitempty"` // when the artwork was moved to the trash
+	CreatedAt         time.Time          `gorm:"index" json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+
+	// ImageURLs is filled by handlers with signed image URLs; it is not stored
+	ImageURLs *ArtworkImageURLs `gorm:"-" json:"image_urls,omitempty"`
 
 	Activity Activity `gorm:"foreignKey:ActivityID" json:"activity,omitempty"`
 	User     User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+
+	// Pages holds the pages after the first of a multi-page artwork; it is only loaded where needed
+	Pages []ArtworkPage `gorm:"foreignKey:ArtworkID" json:"pages,omitempty"`
+}
+
+// ArtworkImageURLs holds signed, expiring URLs of an artwork's image renditions
+// They can be used directly in <img src> without an Authorization header
+type ArtworkImageURLs struct {
+	Thumb     string    `json:"thumb"`
+	Preview   string    `json:"preview"`
+	Original  string    `json:"original"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // TableName specifies the table name for Artwork model
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// ArtworkPage is an additional image of a multi-page artwork, such as a comic or a series
+// The artwork's own file is page 1; additional pages are numbered from 2 in reading order
+type ArtworkPage struct {
+	ID               uint              `gorm:"primaryKey" json:"-"`
+	ArtworkID        uint              `gorm:"not null;uniqueIndex:idx_artwork_page,priority:1" json:"-"`
+	Page             int               `gorm:"not null;uniqueIndex:idx_artwork_page,priority:2" json:"page"`
+	BlobID           *uint             `gorm:"index:idx_artwork_pages_blob_id" json:"-"`
+	FilePath         string            `gorm:"not null;size:500" json:"-"`
+	FileName         string            `gorm:"not null;size:255" json:"file_name"`
+	OriginalMetadata map[string]string `gorm:"type:json;serializer:json" json:"-"`
+	ContentHash      string            `gorm:"not null;size:64;default:''" json:"-"`
+	PerceptualHash   *uint64           `json:"-"`
+	CreatedAt        time.Time         `json:"created_at"`
+
+	// ImageURLs is filled by handlers with signed image URLs; it is not stored
+	ImageURLs *ArtworkImageURLs `gorm:"-" json:"image_urls,omitempty"`
+}
+
+// TableName specifies the table name for ArtworkPage model
+func (ArtworkPage) TableName() string {
+	return "artwork_pages"
+}
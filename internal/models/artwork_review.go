@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// ArtworkReview records a single review decision made on an artwork
+type ArtworkReview struct {
+	ID             uint         `gorm:"primaryKey" json:"id"`
+	ArtworkID      uint         `gorm:"not null;index:idx_artwork_reviews_artwork" json:"artwork_id"`
+	ReviewerID     *uint        `gorm:"index:idx_artwork_reviews_reviewer" json:"reviewer_id"` // nil once the reviewer's account is deleted
+	PreviousStatus ReviewStatus `gorm:"type:enum('draft','pending','approved','rejected');not null" json:"previous_status"`
+	NewStatus      ReviewStatus `gorm:"type:enum('pending','approved','rejected');not null" json:"new_status"`
+	Comment        string       `gorm:"type:text" json:"comment"`
+	CreatedAt      time.Time    `gorm:"index" json:"created_at"`
+
+	Artwork  *Artwork `gorm:"foreignKey:ArtworkID" json:"artwork,omitempty"`
+	Reviewer *User    `gorm:"foreignKey:ReviewerID" json:"reviewer,omitempty"`
+}
+
+// TableName specifies the table name for ArtworkReview model
+func (ArtworkReview) TableName() string {
+	return "artwork_reviews"
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// ArtworkRevision records a previous file of a page of an artwork that has since been replaced
+// Revisions are numbered per artwork across all of its pages
+type ArtworkRevision struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ArtworkID  uint      `gorm:"not null;uniqueIndex:idx_artwork_revision,priority:1" json:"artwork_id"`
+	Revision   int       `gorm:"not null;uniqueIndex:idx_artwork_revision,priority:2" json:"revision"`
+	Page       int       `gorm:"not null;default:1" json:"page"`
+	BlobID     *uint     `gorm:"index:idx_artwork_revisions_blob_id" json:"-"`
+	FilePath   string    `gorm:"not null;size:500" json:"-"`
+	FileName   string    `gorm:"not null;size:255" json:"file_name"`
+	UploadedAt time.Time `gorm:"not null" json:"uploaded_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for ArtworkRevision model
+func (ArtworkRevision) TableName() string {
+	return "artwork_revisions"
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Blob is a stored file addressed by the SHA-256 of its content
+// Artworks and revisions with identical files share one blob; RefCount counts
+// those references and the file is deleted when the last one goes away
+type Blob struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Hash      string    `gorm:"not null;size:64;uniqueIndex:idx_blobs_hash" json:"hash"`
+	Path      string    `gorm:"not null;size:500" json:"-"`
+	Size      int64     `gorm:"not null" json:"size"`
+	RefCount  int       `gorm:"not null;default:0" json:"ref_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for Blob model
+func (Blob) TableName() string {
+	return "blobs"
+}
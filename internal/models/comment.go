@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Comment is a user's comment on an artwork, either starting a thread or replying to another comment
+// RootID points to the comment that started the thread, so a whole thread can be loaded at once
+type Comment struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	ArtworkID uint       `gorm:"not null;index:idx_comments_artwork_root,priority:1" json:"artwork_id"`
+	RootID    *uint      `gorm:"index:idx_comments_artwork_root,priority:2" json:"root_id"`
+	ParentID  *uint      `gorm:"index" json:"parent_id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Content   string     `gorm:"type:text;not null" json:"content"`
+	IsHidden  bool       `gorm:"default:false;not null;index" json:"is_hidden"`
+	HiddenBy  *uint      `json:"hidden_by,omitempty"`
+	HiddenAt  *time.Time `json:"hidden_at,omitempty"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName specifies the table name for Comment model
+func (Comment) TableName() string {
+	return "comments"
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+// RubricCriterion represents a single weighted criterion of an activity's scoring rubric
+type RubricCriterion struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ActivityID  uint      `gorm:"not null;index:idx_rubric_activity" json:"activity_id"`
+	Name        string    `gorm:"not null;size:100" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	Weight      float64   `gorm:"not null" json:"weight"`
+	MinScore    float64   `gorm:"not null;default:0" json:"min_score"`
+	MaxScore    float64   `gorm:"not null;default:10" json:"max_score"`
+	SortOrder   int       `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for RubricCriterion model
+func (RubricCriterion) TableName() string {
+	return "rubric_criteria"
+}
+
+// ActivityJudge assigns a user as a judge of an activity
+type ActivityJudge struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ActivityID uint      `gorm:"not null;uniqueIndex:idx_activity_judge,priority:1" json:"activity_id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_activity_judge,priority:2;index" json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName specifies the table name for ActivityJudge model
+func (ActivityJudge) TableName() string {
+	return "activity_judges"
+}
+
+// ArtworkScore represents a judge's score for an artwork against one rubric criterion
+type ArtworkScore struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ArtworkID   uint      `gorm:"not null;uniqueIndex:idx_artwork_criterion_judge,priority:1" json:"artwork_id"`
+	CriterionID uint      `gorm:"not null;uniqueIndex:idx_artwork_criterion_judge,priority:2" json:"criterion_id"`
+	JudgeID     uint      `gorm:"not null;uniqueIndex:idx_artwork_criterion_judge,priority:3;index" json:"judge_id"`
+	Score       float64   `gorm:"not null" json:"score"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for ArtworkScore model
+func (ArtworkScore) TableName() string {
+	return "artwork_scores"
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// UploadQuota is the per-user, per-activity row locked while checking the upload
+// limit and creating an artwork, so that concurrent uploads by the same user to the
+// same activity are counted one at a time
+type UploadQuota struct {
+	UserID     uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	ActivityID uint      `gorm:"primaryKey;autoIncrement:false" json:"activity_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for UploadQuota model
+func (UploadQuota) TableName() string {
+	return "upload_quotas"
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// Vote is a user's public vote for an approved artwork in an activity
+// Each user can vote for an artwork once; the activity may also cap the votes per user
+type Vote struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ActivityID uint      `gorm:"not null;index:idx_votes_activity_user,priority:1" json:"activity_id"`
+	ArtworkID  uint      `gorm:"not null;uniqueIndex:idx_vote_artwork_user,priority:1" json:"artwork_id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_vote_artwork_user,priority:2;index:idx_votes_activity_user,priority:2" json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for Vote model
+func (Vote) TableName() string {
+	return "votes"
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"art-collection-system/internal/models"
+	"database/sql"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ArtworkRepository handles artwork data access operations
@@ -16,18 +19,161 @@ func NewArtworkRepository(db *gorm.DB) *ArtworkRepository {
 	return &ArtworkRepository{db: db}
 }
 
+// notDeleted restricts a query to artworks that are not in the trash
+func notDeleted(db *gorm.DB) *gorm.DB {
+	return db.Where("artworks.is_deleted = ?", false)
+}
+
 // Create creates a new artwork in the database
 func (r *ArtworkRepository) Create(artwork *models.Artwork) error {
 	return r.db.Create(artwork).Error
 }
 
-// Delete deletes an artwork from the database
+// CreateWithinLimit creates an artwork unless the user already has limit submitted artworks
+// in the activity, and reports whether it was created
+func (r *ArtworkRepository) CreateWithinLimit(artwork *models.Artwork, limit int) (bool, error) {
+	return r.withinUploadLimit(artwork.UserID, artwork.ActivityID, limit, func(tx *gorm.DB) error {
+		return tx.Create(artwork).Error
+	})
+}
+
+// SubmitDraft moves a draft into the review queue unless the user already has limit submitted
+// artworks in the activity, and reports whether it was submitted. duplicateOfID replaces the
+// draft's duplicate flag. The submission is recorded in the review history. It returns
+// gorm.ErrRecordNotFound when the artwork is no longer a draft.
+func (r *ArtworkRepository) SubmitDraft(artwork *models.Artwork, limit int, duplicateOfID *uint) (bool, error) {
+	return r.withinUploadLimit(artwork.UserID, artwork.ActivityID, limit, func(tx *gorm.DB) error {
+		result := tx.Model(&models.Artwork{}).Scopes(notDeleted).
+			Where("id = ? AND review_status = ?", artwork.ID, models.StatusDraft).
+			Updates(map[string]interface{}{
+				"review_status":   models.StatusPending,
+				"duplicate_of_id": duplicateOfID,
+				"updated_at":      time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		artwork.ReviewStatus = models.StatusPending
+		artwork.DuplicateOfID = duplicateOfID
+		return tx.Create(&models.ArtworkReview{
+			ArtworkID:      artwork.ID,
+			ReviewerID:     &artwork.UserID,
+			PreviousStatus: models.StatusDraft,
+			NewStatus:      models.StatusPending,
+			Comment:        "作者提交了草稿",
+		}).Error
+	})
+}
+
+// withinUploadLimit runs fn in a transaction unless the user already has limit submitted
+// artworks in the activity, and reports whether fn ran. The user's quota row for the activity
+// is locked while counting, so concurrent uploads cannot all pass the check and exceed the limit.
+func (r *ArtworkRepository) withinUploadLimit(userID, activityID uint, limit int, fn func(tx *gorm.DB) error) (bool, error) {
+	// Create the quota row in its own statement: inserting it inside the transaction would
+	// make two first uploads wait on each other's insert locks and deadlock
+	quota := &models.UploadQuota{UserID: userID, ActivityID: activityID}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(quota).Error; err != nil {
+		return false, err
+	}
+
+	ran := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND activity_id = ?", userID, activityID).
+			First(&models.UploadQuota{}).Error
+		if err != nil {
+			return err
+		}
+
+		// The count is the transaction's first consistent read, so it sees every
+		// artwork committed by the previous holder of the lock
+		var count int64
+		err = tx.Model(&models.Artwork{}).Scopes(notDeleted).
+			Where("user_id = ? AND activity_id = ? AND review_status <> ?", userID, activityID, models.StatusDraft).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count >= int64(limit) {
+			return nil
+		}
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		ran = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return ran, nil
+}
+
+// GetExpiredDrafts retrieves up to limit drafts whose activity has passed its deadline or been deleted
+func (r *ArtworkRepository) GetExpiredDrafts(now time.Time, limit int) ([]models.Artwork, error) {
+	var artworks []models.Artwork
+	err := r.db.Model(&models.Artwork{}).Scopes(notDeleted).
+		Joins("JOIN activities ON activities.id = artworks.activity_id").
+		Where("artworks.review_status = ?", models.StatusDraft).
+		Where("activities.is_deleted = ? OR (activities.deadline IS NOT NULL AND activities.deadline < ?)", true, now).
+		Order("artworks.id ASC").
+		Limit(limit).
+		Find(&artworks).Error
+	if err != nil {
+		return nil, err
+	}
+	return artworks, nil
+}
+
+// Delete permanently deletes an artwork from the database
 func (r *ArtworkRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Artwork{}, id).Error
 }
 
-// GetByID retrieves an artwork by ID
-func (r *ArtworkRepository) GetByID(id uint) (*models.Artwork, error) {
+// SoftDelete moves an artwork to the trash
+func (r *ArtworkRepository) SoftDelete(id uint) error {
+	return r.db.Model(&models.Artwork{}).Where("id = ? AND is_deleted = ?", id, false).Updates(map[string]interface{}{
+		"is_deleted": true,
+		"deleted_at": time.Now(),
+	}).Error
+}
+
+// Restore takes an artwork out of the trash. Drafts are always restored; any other artwork
+// counts toward the activity's upload limit again, so it is only restored while the user
+// has fewer than limit submitted artworks in the activity. It reports whether it was restored.
+func (r *ArtworkRepository) Restore(artwork *models.Artwork, limit int) (bool, error) {
+	restore := func(tx *gorm.DB) error {
+		result := tx.Model(&models.Artwork{}).Where("id = ? AND is_deleted = ?", artwork.ID, true).Updates(map[string]interface{}{
+			"is_deleted": false,
+			"deleted_at": nil,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		artwork.IsDeleted = false
+		artwork.DeletedAt = nil
+		return nil
+	}
+
+	if artwork.ReviewStatus == models.StatusDraft {
+		if err := restore(r.db); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return r.withinUploadLimit(artwork.UserID, artwork.ActivityID, limit, restore)
+}
+
+// GetByIDIncludingDeleted retrieves an artwork by ID, whether or not it is in the trash
+func (r *ArtworkRepository) GetByIDIncludingDeleted(id uint) (*models.Artwork, error) {
 	var artwork models.Artwork
 	err := r.db.First(&artwork, id).Error
 	if err != nil {
@@ -36,10 +182,74 @@ func (r *ArtworkRepository) GetByID(id uint) (*models.Artwork, error) {
 	return &artwork, nil
 }
 
+// GetDeletedByID retrieves an artwork in the trash by ID
+func (r *ArtworkRepository) GetDeletedByID(id uint) (*models.Artwork, error) {
+	var artwork models.Artwork
+	err := r.db.Where("is_deleted = ?", true).First(&artwork, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &artwork, nil
+}
+
+// GetDeletedWithPagination retrieves the artworks in the trash with their activity and pages, most recently
+// deleted first. userID limits them to one author's artworks (0 lists every author's).
+func (r *ArtworkRepository) GetDeletedWithPagination(userID uint, page, pageSize int) ([]models.Artwork, int64, error) {
+	var artworks []models.Artwork
+	var total int64
+
+	query := func() *gorm.DB {
+		q := r.db.Model(&models.Artwork{}).Where("is_deleted = ?", true)
+		if userID != 0 {
+			q = q.Where("user_id = ?", userID)
+		}
+		return q
+	}
+
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query().Preload("Activity").Preload("User").Preload("Pages", orderPages).
+		Order("deleted_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&artworks).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return artworks, total, nil
+}
+
+// GetDeletedBefore retrieves up to limit artworks moved to the trash before the given time
+func (r *ArtworkRepository) GetDeletedBefore(before time.Time, limit int) ([]models.Artwork, error) {
+	var artworks []models.Artwork
+	err := r.db.Where("is_deleted = ? AND deleted_at < ?", true, before).
+		Order("id ASC").
+		Limit(limit).
+		Find(&artworks).Error
+	if err != nil {
+		return nil, err
+	}
+	return artworks, nil
+}
+
+// GetByID retrieves an artwork by ID, unless it is in the trash
+func (r *ArtworkRepository) GetByID(id uint) (*models.Artwork, error) {
+	var artwork models.Artwork
+	err := r.db.Scopes(notDeleted).First(&artwork, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &artwork, nil
+}
+
 // GetByIDWithRelations retrieves an artwork by ID with related activity and user data
 func (r *ArtworkRepository) GetByIDWithRelations(id uint) (*models.Artwork, error) {
 	var artwork models.Artwork
-	err := r.db.Preload("Activity").Preload("User").First(&artwork, id).Error
+	err := r.db.Preload("Activity").Preload("User").Scopes(notDeleted).First(&artwork, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +259,7 @@ func (r *ArtworkRepository) GetByIDWithRelations(id uint) (*models.Artwork, erro
 // GetByUserID retrieves all artworks by a specific user
 func (r *ArtworkRepository) GetByUserID(userID uint) ([]models.Artwork, error) {
 	var artworks []models.Artwork
-	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&artworks).Error
+	err := r.db.Scopes(notDeleted).Where("user_id = ?", userID).Order("created_at DESC").Find(&artworks).Error
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +269,7 @@ func (r *ArtworkRepository) GetByUserID(userID uint) ([]models.Artwork, error) {
 // GetByUserIDWithRelations retrieves all artworks by a specific user with related data
 func (r *ArtworkRepository) GetByUserIDWithRelations(userID uint) ([]models.Artwork, error) {
 	var artworks []models.Artwork
-	err := r.db.Preload("Activity").Where("user_id = ?", userID).Order("created_at DESC").Find(&artworks).Error
+	err := r.db.Preload("Activity").Preload("Pages", orderPages).Scopes(notDeleted).Where("user_id = ?", userID).Order("created_at DESC").Find(&artworks).Error
 	if err != nil {
 		return nil, err
 	}
@@ -69,20 +279,132 @@ func (r *ArtworkRepository) GetByUserIDWithRelations(userID uint) ([]models.Artw
 // GetByActivityID retrieves all artworks for a specific activity
 func (r *ArtworkRepository) GetByActivityID(activityID uint) ([]models.Artwork, error) {
 	var artworks []models.Artwork
-	err := r.db.Where("activity_id = ?", activityID).Order("created_at DESC").Find(&artworks).Error
+	err := r.db.Scopes(notDeleted).Where("activity_id = ?", activityID).Order("created_at DESC").Find(&artworks).Error
+	if err != nil {
+		return nil, err
+	}
+	return artworks, nil
+}
+
+// GetByActivityIDAndStatus retrieves the artworks of an activity with a given review status and their pages, oldest first
+func (r *ArtworkRepository) GetByActivityIDAndStatus(activityID uint, status models.ReviewStatus) ([]models.Artwork, error) {
+	var artworks []models.Artwork
+	err := r.db.Preload("User").Preload("Pages", orderPages).Scopes(notDeleted).
+		Where("activity_id = ? AND review_status = ?", activityID, status).
+		Order("created_at ASC").
+		Find(&artworks).Error
 	if err != nil {
 		return nil, err
 	}
 	return artworks, nil
 }
 
-// GetByActivityIDWithPagination retrieves artworks for a specific activity with pagination
-func (r *ArtworkRepository) GetByActivityIDWithPagination(activityID uint, page, pageSize int) ([]models.Artwork, int64, error) {
+// GetForExport retrieves the submitted artworks of an activity with their authors and pages, grouped by author
+// When statuses is not empty only artworks with one of those review statuses are returned
+func (r *ArtworkRepository) GetForExport(activityID uint, statuses []models.ReviewStatus) ([]models.Artwork, error) {
+	var artworks []models.Artwork
+	query := r.db.Preload("User").Preload("Pages", orderPages).Scopes(notDeleted).
+		Where("activity_id = ? AND review_status <> ?", activityID, models.StatusDraft)
+	if len(statuses) > 0 {
+		query = query.Where("review_status IN ?", statuses)
+	}
+	if err := query.Order("user_id ASC, id ASC").Find(&artworks).Error; err != nil {
+		return nil, err
+	}
+	return artworks, nil
+}
+
+// ArtworkRow is an artwork with the names of its activity and author, as read by streaming queries
+type ArtworkRow struct {
+	models.Artwork
+	ActivityName   string
+	AuthorNickname string
+	AuthorEmail    string
+}
+
+// artworkRowQuery builds a query selecting artworks outside the trash joined with their activity and author
+func (r *ArtworkRepository) artworkRowQuery() *gorm.DB {
+	return r.db.Model(&models.Artwork{}).Scopes(notDeleted).
+		Select("artworks.*, activities.name AS activity_name, users.nickname AS author_nickname, users.email AS author_email").
+		Joins("LEFT JOIN activities ON activities.id = artworks.activity_id").
+		Joins("LEFT JOIN users ON users.id = artworks.user_id")
+}
+
+// streamArtworkRows calls fn for every artwork row returned by query
+func (r *ArtworkRepository) streamArtworkRows(query *gorm.DB, fn func(row *ArtworkRow) error) error {
+	return eachRow(query, func(rows *sql.Rows) error {
+		var row ArtworkRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		return fn(&row)
+	})
+}
+
+// StreamByActivityID calls fn for every submitted artwork of an activity, newest first, reading them with a cursor
+// When tag is not empty only artworks whose tags contain it are returned
+func (r *ArtworkRepository) StreamByActivityID(activityID uint, tag string, fn func(row *ArtworkRow) error) error {
+	query := r.artworkRowQuery().
+		Where("artworks.activity_id = ? AND artworks.review_status <> ?", activityID, models.StatusDraft)
+	if tag != "" {
+		query = query.Where("JSON_CONTAINS(artworks.tags, JSON_QUOTE(?))", tag)
+	}
+	return r.streamArtworkRows(query.Order("artworks.created_at DESC"), fn)
+}
+
+// StreamReviewQueue calls fn for every pending artwork, oldest first, skipping the excluded artworks
+func (r *ArtworkRepository) StreamReviewQueue(excludeIDs []uint, fn func(row *ArtworkRow) error) error {
+	query := r.artworkRowQuery().Where("artworks.review_status = ?", models.StatusPending)
+	if len(excludeIDs) > 0 {
+		query = query.Where("artworks.id NOT IN ?", excludeIDs)
+	}
+	return r.streamArtworkRows(query.Order("artworks.created_at ASC"), fn)
+}
+
+// GetByActivityIDWithPagination retrieves the submitted artworks for a specific activity with pagination
+// When tag is not empty only artworks whose tags contain it are returned
+func (r *ArtworkRepository) GetByActivityIDWithPagination(activityID uint, page, pageSize int, tag string) ([]models.Artwork, int64, error) {
 	var artworks []models.Artwork
 	var total int64
 
+	query := func() *gorm.DB {
+		q := r.db.Model(&models.Artwork{}).Scopes(notDeleted).Where("activity_id = ? AND review_status <> ?", activityID, models.StatusDraft)
+		if tag != "" {
+			q = q.Where("JSON_CONTAINS(tags, JSON_QUOTE(?))", tag)
+		}
+		return q
+	}
+
 	// Count total artworks for this activity
-	if err := r.db.Model(&models.Artwork{}).Where("activity_id = ?", activityID).Count(&total).Error; err != nil {
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+
+	// Retrieve paginated artworks with user information and pages
+	err := query().Preload("User").Preload("Pages", orderPages).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&artworks).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return artworks, total, nil
+}
+
+// GetByActivityIDAndStatusWithPagination retrieves artworks of an activity with a given review status
+// with pagination, newest first
+func (r *ArtworkRepository) GetByActivityIDAndStatusWithPagination(activityID uint, status models.ReviewStatus, page, pageSize int) ([]models.Artwork, int64, error) {
+	var artworks []models.Artwork
+	var total int64
+
+	// Count total matching artworks
+	if err := r.db.Model(&models.Artwork{}).Scopes(notDeleted).Where("activity_id = ? AND review_status = ?", activityID, status).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -90,8 +412,8 @@ func (r *ArtworkRepository) GetByActivityIDWithPagination(activityID uint, page,
 	offset := (page - 1) * pageSize
 
 	// Retrieve paginated artworks with user information
-	err := r.db.Preload("User").
-		Where("activity_id = ?", activityID).
+	err := r.db.Preload("User").Scopes(notDeleted).
+		Where("activity_id = ? AND review_status = ?", activityID, status).
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(pageSize).
@@ -104,22 +426,155 @@ func (r *ArtworkRepository) GetByActivityIDWithPagination(activityID uint, page,
 	return artworks, total, nil
 }
 
-// GetReviewQueue retrieves artworks pending review with pagination
-func (r *ArtworkRepository) GetReviewQueue(page, pageSize int) ([]models.Artwork, int64, error) {
+// GetByIDWithUser retrieves an artwork by ID with its author
+func (r *ArtworkRepository) GetByIDWithUser(id uint) (*models.Artwork, error) {
+	var artwork models.Artwork
+	err := r.db.Preload("User").Scopes(notDeleted).First(&artwork, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &artwork, nil
+}
+
+// GetBatchAfterID retrieves up to limit artworks with an ID greater than afterID, ordered by ID
+// Used by maintenance commands to walk all artworks in batches, including those in the trash
+func (r *ArtworkRepository) GetBatchAfterID(afterID uint, limit int) ([]models.Artwork, error) {
+	var artworks []models.Artwork
+	err := r.db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&artworks).Error
+	if err != nil {
+		return nil, err
+	}
+	return artworks, nil
+}
+
+// GetByActivityAndContentHash retrieves the submitted artworks of an activity whose file has the given
+// SHA-256, excluding the artwork with excludeID (0 excludes nothing)
+func (r *ArtworkRepository) GetByActivityAndContentHash(activityID uint, contentHash string, excludeID uint) ([]models.Artwork, error) {
+	var artworks []models.Artwork
+	err := r.db.Scopes(notDeleted).Where("activity_id = ? AND content_hash = ? AND id <> ? AND review_status <> ?", activityID, contentHash, excludeID, models.StatusDraft).
+		Order("id ASC").
+		Find(&artworks).Error
+	if err != nil {
+		return nil, err
+	}
+	return artworks, nil
+}
+
+// DuplicateCandidate is an artwork whose file matches another artwork's file exactly or perceptually
+type DuplicateCandidate struct {
+	ID          uint
+	ActivityID  uint
+	UserID      uint
+	Title       string
+	ContentHash string
+	Distance    *int
+}
+
+// FindDuplicateCandidates retrieves submitted artworks, across all activities, whose file has the same
+// SHA-256 or whose perceptual hash is within maxDistance bits, closest first
+func (r *ArtworkRepository) FindDuplicateCandidates(artwork *models.Artwork, maxDistance, limit int) ([]DuplicateCandidate, error) {
+	candidates := make([]DuplicateCandidate, 0)
+
+	// Artworks uploaded before hashing was introduced have no hashes to compare
+	if artwork.ContentHash == "" && artwork.PerceptualHash == nil {
+		return candidates, nil
+	}
+
+	query := r.db.Model(&models.Artwork{}).Scopes(notDeleted).Where("id <> ? AND review_status <> ?", artwork.ID, models.StatusDraft)
+	match := r.db.Where("content_hash = ? AND content_hash <> ''", artwork.ContentHash)
+
+	if artwork.PerceptualHash != nil {
+		distance := "BIT_COUNT(perceptual_hash ^ ?)"
+		match = match.Or("perceptual_hash IS NOT NULL AND "+distance+" <= ?", *artwork.PerceptualHash, maxDistance)
+		query = query.
+			Select("id, activity_id, user_id, title, content_hash, "+distance+" AS distance", *artwork.PerceptualHash).
+			Order("distance ASC")
+	} else {
+		query = query.Select("id, activity_id, user_id, title, content_hash")
+	}
+
+	err := query.Where(match).Order("id ASC").Limit(limit).Scan(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// FindExactDuplicateCandidates retrieves submitted artworks, across all activities, whose file has the
+// same SHA-256 as the file of any of the given artworks, oldest first
+// The given artworks match themselves; callers must skip those matches.
+func (r *ArtworkRepository) FindExactDuplicateCandidates(artworks []models.Artwork) ([]DuplicateCandidate, error) {
+	candidates := make([]DuplicateCandidate, 0)
+
+	hashes := make([]string, 0, len(artworks))
+	for _, artwork := range artworks {
+		// Artworks uploaded before hashing was introduced have no hash to compare
+		if artwork.ContentHash != "" {
+			hashes = append(hashes, artwork.ContentHash)
+		}
+	}
+	if len(hashes) == 0 {
+		return candidates, nil
+	}
+
+	err := r.db.Model(&models.Artwork{}).Scopes(notDeleted).
+		Select("id, activity_id, user_id, title, content_hash").
+		Where("content_hash IN ? AND review_status <> ?", hashes, models.StatusDraft).
+		Order("id ASC").
+		Scan(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// UpdateHashes updates the content and perceptual hashes of an artwork's file
+func (r *ArtworkRepository) UpdateHashes(id uint, contentHash string, perceptualHash *uint64) error {
+	return r.db.Model(&models.Artwork{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"content_hash":    contentHash,
+		"perceptual_hash": perceptualHash,
+	}).Error
+}
+
+// MoveToBlob points an artwork stored before content-addressed storage at a blob
+// It only applies while the artwork still has the given legacy file, and reports whether it did
+func (r *ArtworkRepository) MoveToBlob(id uint, legacyPath string, blob *models.Blob) (bool, error) {
+	result := r.db.Model(&models.Artwork{}).
+		Where("id = ? AND blob_id IS NULL AND file_path = ?", id, legacyPath).
+		UpdateColumns(map[string]interface{}{
+			"blob_id":   blob.ID,
+			"file_path": blob.Path,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MoveRevisionToBlob points a revision stored before content-addressed storage at a blob
+// It only applies while the revision still has the given legacy file, and reports whether it did
+func (r *ArtworkRepository) MoveRevisionToBlob(id uint, legacyPath string, blob *models.Blob) (bool, error) {
+	result := r.db.Model(&models.ArtworkRevision{}).
+		Where("id = ? AND blob_id IS NULL AND file_path = ?", id, legacyPath).
+		UpdateColumns(map[string]interface{}{
+			"blob_id":   blob.ID,
+			"file_path": blob.Path,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetReviewQueue retrieves artworks pending review with pagination, skipping the excluded artworks
+func (r *ArtworkRepository) GetReviewQueue(page, pageSize int, excludeIDs []uint) ([]models.Artwork, int64, error) {
 	var artworks []models.Artwork
 	var total int64
 
 	// Count total pending artworks
-	if err := r.db.Model(&models.Artwork{}).Where("review_status = ?", models.StatusPending).Count(&total).Error; err != nil {
+	if err := r.pendingQuery(excludeIDs).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Calculate offset
 	offset := (page - 1) * pageSize
 
-	// Retrieve paginated pending artworks with user and activity information
-	err := r.db.Preload("User").Preload("Activity").
-		Where("review_status = ?", models.StatusPending).
+	// Retrieve paginated pending artworks with user and activity information and pages
+	err := r.pendingQuery(excludeIDs).Preload("User").Preload("Activity").Preload("Pages", orderPages).
 		Order("created_at ASC").
 		Offset(offset).
 		Limit(pageSize).
@@ -132,11 +587,48 @@ func (r *ArtworkRepository) GetReviewQueue(page, pageSize int) ([]models.Artwork
 	return artworks, total, nil
 }
 
-// CountByUserAndActivity counts artworks by a user in a specific activity
+// GetPendingIDs retrieves the IDs of the oldest pending artworks, skipping the excluded artworks
+func (r *ArtworkRepository) GetPendingIDs(excludeIDs []uint, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.pendingQuery(excludeIDs).Order("created_at ASC").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// pendingQuery builds a query for pending artworks, skipping the excluded artworks
+func (r *ArtworkRepository) pendingQuery(excludeIDs []uint) *gorm.DB {
+	query := r.db.Model(&models.Artwork{}).Scopes(notDeleted).Where("review_status = ?", models.StatusPending)
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
+	}
+	return query
+}
+
+// GetByIDsWithRelations retrieves artworks by IDs with related activity, user and page data, oldest first
+func (r *ArtworkRepository) GetByIDsWithRelations(ids []uint) ([]models.Artwork, error) {
+	var artworks []models.Artwork
+	if len(ids) == 0 {
+		return artworks, nil
+	}
+
+	err := r.db.Preload("User").Preload("Activity").Preload("Pages", orderPages).Scopes(notDeleted).
+		Where("id IN ?", ids).
+		Order("created_at ASC").
+		Find(&artworks).Error
+	if err != nil {
+		return nil, err
+	}
+	return artworks, nil
+}
+
+// CountByUserAndActivity counts the submitted artworks by a user in a specific activity
+// Drafts do not count toward the activity's upload limit
 func (r *ArtworkRepository) CountByUserAndActivity(userID, activityID uint) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Artwork{}).
-		Where("user_id = ? AND activity_id = ?", userID, activityID).
+	err := r.db.Model(&models.Artwork{}).Scopes(notDeleted).
+		Where("user_id = ? AND activity_id = ? AND review_status <> ?", userID, activityID, models.StatusDraft).
 		Count(&count).Error
 	if err != nil {
 		return 0, err
@@ -144,14 +636,420 @@ func (r *ArtworkRepository) CountByUserAndActivity(userID, activityID uint) (int
 	return count, nil
 }
 
-// UpdateReviewStatus updates the review status of a single artwork
-func (r *ArtworkRepository) UpdateReviewStatus(id uint, status models.ReviewStatus) error {
-	return r.db.Model(&models.Artwork{}).Where("id = ?", id).Update("review_status", status).Error
+// reviewColumns lists the columns written when an artwork's review decision changes
+var reviewColumns = []string{"review_status", "reject_reason", "reject_reason_codes", "updated_at"}
+
+// ReviewUpdate describes a review decision to apply to one or more artworks
+type ReviewUpdate struct {
+	ReviewerID        uint
+	Status            models.ReviewStatus
+	RejectReason      string
+	RejectReasonCodes []models.RejectReasonCode
+	Comment           string
+}
+
+// UpdateReviewStatus updates the review status of a single artwork and records the
+// decision in the review history within the same transaction
+func (r *ArtworkRepository) UpdateReviewStatus(id uint, update ReviewUpdate) error {
+	_, err := r.BatchUpdateReviewStatus([]uint{id}, update)
+	return err
 }
 
-// BatchUpdateReviewStatus updates the review status of multiple artworks
-func (r *ArtworkRepository) BatchUpdateReviewStatus(ids []uint, status models.ReviewStatus) error {
-	return r.db.Model(&models.Artwork{}).Where("id IN ?", ids).Update("review_status", status).Error
+// BatchUpdateReviewStatus updates the review status of multiple artworks and records each
+// decision in the review history within the same transaction. Artworks that do not exist,
+// are in the trash or are drafts are skipped; it returns the number of artworks updated.
+func (r *ArtworkRepository) BatchUpdateReviewStatus(ids []uint, update ReviewUpdate) (int, error) {
+	var updated int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the affected rows so the recorded previous status stays accurate;
+		// drafts have not been submitted and cannot be reviewed
+		var artworks []models.Artwork
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(notDeleted).
+			Select("id", "review_status").
+			Where("id IN ? AND review_status <> ?", ids, models.StatusDraft).
+			Find(&artworks).Error
+		if err != nil {
+			return err
+		}
+		if len(artworks) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		foundIDs := make([]uint, 0, len(artworks))
+		reviews := make([]models.ArtworkReview, 0, len(artworks))
+		for _, artwork := range artworks {
+			foundIDs = append(foundIDs, artwork.ID)
+			reviews = append(reviews, models.ArtworkReview{
+				ArtworkID:      artwork.ID,
+				ReviewerID:     &update.ReviewerID,
+				PreviousStatus: artwork.ReviewStatus,
+				NewStatus:      update.Status,
+				Comment:        update.Comment,
+			})
+		}
+
+		err = tx.Model(&models.Artwork{}).Where("id IN ?", foundIDs).Select(reviewColumns).Updates(&models.Artwork{
+			ReviewStatus:      update.Status,
+			RejectReason:      update.RejectReason,
+			RejectReasonCodes: update.RejectReasonCodes,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(&reviews).Error; err != nil {
+			return err
+		}
+		updated = len(foundIDs)
+		return nil
+	})
+	return updated, err
+}
+
+// FileReplacement describes the new file of an artwork
+type FileReplacement struct {
+	BlobID           *uint
+	FilePath         string
+	FileName         string
+	OriginalMetadata map[string]string
+	ContentHash      string
+	PerceptualHash   *uint64
+	DuplicateOfID    *uint
+}
+
+// ReplaceFile points a page of an artwork at a new file within a transaction: the current
+// file is kept as a revision, the artwork's revision number is bumped and the artwork goes
+// back to pending review, unless it is still a draft. Page 1 is the artwork's own file. A
+// review history entry is written when the review status changes.
+func (r *ArtworkRepository) ReplaceFile(id, userID uint, page int, replacement FileReplacement) (*models.Artwork, error) {
+	var artwork models.Artwork
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(notDeleted).First(&artwork, id).Error; err != nil {
+			return err
+		}
+
+		revision := &models.ArtworkRevision{
+			ArtworkID: artwork.ID,
+			Revision:  artwork.Revision,
+			Page:      page,
+		}
+		var artworkPage models.ArtworkPage
+		if page == 1 {
+			revision.BlobID = artwork.BlobID
+			revision.FilePath = artwork.FilePath
+			revision.FileName = artwork.FileName
+			revision.UploadedAt = artwork.CreatedAt
+			if artwork.FileUpdatedAt != nil {
+				revision.UploadedAt = *artwork.FileUpdatedAt
+			}
+		} else {
+			if err := tx.Where("artwork_id = ? AND page = ?", artwork.ID, page).First(&artworkPage).Error; err != nil {
+				return err
+			}
+			revision.BlobID = artworkPage.BlobID
+			revision.FilePath = artworkPage.FilePath
+			revision.FileName = artworkPage.FileName
+
+			// The page's file was uploaded when the page was added, or when it last replaced an earlier file
+			revision.UploadedAt = artworkPage.CreatedAt
+			var previous models.ArtworkRevision
+			err := tx.Where("artwork_id = ? AND page = ?", artwork.ID, page).Order("revision DESC").Limit(1).Find(&previous).Error
+			if err != nil {
+				return err
+			}
+			if previous.ID != 0 {
+				revision.UploadedAt = previous.CreatedAt
+			}
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+
+		draft := artwork.ReviewStatus == models.StatusDraft
+		if !draft && artwork.ReviewStatus != models.StatusPending {
+			review := &models.ArtworkReview{
+				ArtworkID:      artwork.ID,
+				ReviewerID:     &userID,
+				PreviousStatus: artwork.ReviewStatus,
+				NewStatus:      models.StatusPending,
+				Comment:        "作者更新了作品文件，重新进入审核",
+			}
+			if err := tx.Create(review).Error; err != nil {
+				return err
+			}
+		}
+
+		columns := []string{"revision", "review_status", "reject_reason", "reject_reason_codes", "updated_at"}
+		if page == 1 {
+			now := time.Now()
+			artwork.BlobID = replacement.BlobID
+			artwork.FilePath = replacement.FilePath
+			artwork.FileName = replacement.FileName
+			artwork.OriginalMetadata = replacement.OriginalMetadata
+			artwork.ContentHash = replacement.ContentHash
+			artwork.PerceptualHash = replacement.PerceptualHash
+			artwork.DuplicateOfID = replacement.DuplicateOfID
+			artwork.FileUpdatedAt = &now
+			columns = append(columns, append(pageFileColumns, "duplicate_of_id", "file_updated_at")...)
+		} else {
+			artworkPage.BlobID = replacement.BlobID
+			artworkPage.FilePath = replacement.FilePath
+			artworkPage.FileName = replacement.FileName
+			artworkPage.OriginalMetadata = replacement.OriginalMetadata
+			artworkPage.ContentHash = replacement.ContentHash
+			artworkPage.PerceptualHash = replacement.PerceptualHash
+			if err := tx.Model(&artworkPage).Select(pageFileColumns).Updates(&artworkPage).Error; err != nil {
+				return err
+			}
+		}
+		artwork.Revision++
+		if !draft {
+			artwork.ReviewStatus = models.StatusPending
+		}
+		artwork.RejectReason = ""
+		artwork.RejectReasonCodes = nil
+
+		return tx.Model(&artwork).Select(columns).Updates(&artwork).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &artwork, nil
+}
+
+// GetRevisions retrieves the previous revisions of an artwork, newest first
+func (r *ArtworkRepository) GetRevisions(artworkID uint) ([]models.ArtworkRevision, error) {
+	var revisions []models.ArtworkRevision
+	err := r.db.Where("artwork_id = ?", artworkID).Order("revision DESC").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetRevision retrieves a specific previous revision of an artwork
+func (r *ArtworkRepository) GetRevision(artworkID uint, revision int) (*models.ArtworkRevision, error) {
+	var artworkRevision models.ArtworkRevision
+	err := r.db.Where("artwork_id = ? AND revision = ?", artworkID, revision).First(&artworkRevision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &artworkRevision, nil
+}
+
+// GetRevisionsByArtworkIDs retrieves the previous revisions of the given artworks
+func (r *ArtworkRepository) GetRevisionsByArtworkIDs(artworkIDs []uint) ([]models.ArtworkRevision, error) {
+	var revisions []models.ArtworkRevision
+	if len(artworkIDs) == 0 {
+		return revisions, nil
+	}
+	err := r.db.Where("artwork_id IN ?", artworkIDs).Order("id ASC").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// orderPages sorts preloaded artwork pages in reading order
+func orderPages(db *gorm.DB) *gorm.DB {
+	return db.Order("page ASC")
+}
+
+// GetPages retrieves the pages after the first of an artwork in reading order
+func (r *ArtworkRepository) GetPages(artworkID uint) ([]models.ArtworkPage, error) {
+	var pages []models.ArtworkPage
+	err := r.db.Where("artwork_id = ?", artworkID).Order("page ASC").Find(&pages).Error
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// GetPage retrieves a page of an artwork; page 1 is the artwork's own file and has no row
+func (r *ArtworkRepository) GetPage(artworkID uint, page int) (*models.ArtworkPage, error) {
+	var artworkPage models.ArtworkPage
+	err := r.db.Where("artwork_id = ? AND page = ?", artworkID, page).First(&artworkPage).Error
+	if err != nil {
+		return nil, err
+	}
+	return &artworkPage, nil
+}
+
+// AddPages appends pages to an artwork within a transaction, numbering them after its
+// current pages, and sends the artwork back to pending review unless it is still a draft.
+// A review history entry is written when the review status changes. It reports false without adding anything when
+// the artwork would end up with more than maxPages pages.
+func (r *ArtworkRepository) AddPages(id, userID uint, pages []models.ArtworkPage, maxPages int) (*models.Artwork, bool, error) {
+	var artwork models.Artwork
+	added := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(notDeleted).First(&artwork, id).Error; err != nil {
+			return err
+		}
+		if artwork.PageCount+len(pages) > maxPages {
+			return nil
+		}
+
+		for i := range pages {
+			pages[i].ArtworkID = artwork.ID
+			pages[i].Page = artwork.PageCount + i + 1
+		}
+		if err := tx.Create(&pages).Error; err != nil {
+			return err
+		}
+
+		draft := artwork.ReviewStatus == models.StatusDraft
+		if !draft && artwork.ReviewStatus != models.StatusPending {
+			review := &models.ArtworkReview{
+				ArtworkID:      artwork.ID,
+				ReviewerID:     &userID,
+				PreviousStatus: artwork.ReviewStatus,
+				NewStatus:      models.StatusPending,
+				Comment:        "作者添加了作品页面，重新进入审核",
+			}
+			if err := tx.Create(review).Error; err != nil {
+				return err
+			}
+		}
+
+		artwork.PageCount += len(pages)
+		if !draft {
+			artwork.ReviewStatus = models.StatusPending
+		}
+		artwork.RejectReason = ""
+		artwork.RejectReasonCodes = nil
+		if err := tx.Model(&artwork).
+			Select("page_count", "review_status", "reject_reason", "reject_reason_codes", "updated_at").
+			Updates(&artwork).Error; err != nil {
+			return err
+		}
+		added = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &artwork, added, nil
+}
+
+// pageFileColumns lists the columns describing the file shown on a page
+var pageFileColumns = []string{"blob_id", "file_path", "file_name", "original_metadata", "content_hash", "perceptual_hash"}
+
+// ReorderPages rearranges the pages of an artwork within a transaction; order lists the
+// current page numbers in their new order. The files move between the artwork row (page 1)
+// and its page rows, so page numbers and blob references stay as they are. It reports false
+// without changing anything when order does not cover exactly the artwork's current pages.
+func (r *ArtworkRepository) ReorderPages(id uint, order []int) (*models.Artwork, bool, error) {
+	var artwork models.Artwork
+	reordered := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(notDeleted).First(&artwork, id).Error; err != nil {
+			return err
+		}
+		if len(order) != artwork.PageCount {
+			return nil
+		}
+
+		var pages []models.ArtworkPage
+		if err := tx.Where("artwork_id = ?", artwork.ID).Order("page ASC").Find(&pages).Error; err != nil {
+			return err
+		}
+		if len(pages) != artwork.PageCount-1 {
+			return nil
+		}
+
+		// The files in their current order, page 1 first
+		files := make([]models.ArtworkPage, 0, artwork.PageCount)
+		files = append(files, models.ArtworkPage{
+			BlobID:           artwork.BlobID,
+			FilePath:         artwork.FilePath,
+			FileName:         artwork.FileName,
+			OriginalMetadata: artwork.OriginalMetadata,
+			ContentHash:      artwork.ContentHash,
+			PerceptualHash:   artwork.PerceptualHash,
+		})
+		files = append(files, pages...)
+
+		first := files[order[0]-1]
+		artwork.BlobID = first.BlobID
+		artwork.FilePath = first.FilePath
+		artwork.FileName = first.FileName
+		artwork.OriginalMetadata = first.OriginalMetadata
+		artwork.ContentHash = first.ContentHash
+		artwork.PerceptualHash = first.PerceptualHash
+		if err := tx.Model(&artwork).Select(append(pageFileColumns, "updated_at")).Updates(&artwork).Error; err != nil {
+			return err
+		}
+
+		for i := range pages {
+			file := files[order[i+1]-1]
+			pages[i].BlobID = file.BlobID
+			pages[i].FilePath = file.FilePath
+			pages[i].FileName = file.FileName
+			pages[i].OriginalMetadata = file.OriginalMetadata
+			pages[i].ContentHash = file.ContentHash
+			pages[i].PerceptualHash = file.PerceptualHash
+			if err := tx.Model(&pages[i]).Select(pageFileColumns).Updates(&pages[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		artwork.Pages = pages
+		reordered = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &artwork, reordered, nil
+}
+
+// FileReference is a stored file referenced by an artwork, one of its pages or one of its previous revisions
+type FileReference struct {
+	// Kind is "artwork", "page" or "revision"
+	Kind      string
+	ID        uint
+	ArtworkID uint
+	FilePath  string
+}
+
+// GetFileReferences retrieves the stored files of all artworks, pages and previous revisions,
+// including those of artworks in the trash
+func (r *ArtworkRepository) GetFileReferences() ([]FileReference, error) {
+	var references []FileReference
+	err := r.db.Model(&models.Artwork{}).
+		Select("'artwork' AS kind, id, id AS artwork_id, file_path").
+		Scan(&references).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var pageReferences []FileReference
+	err = r.db.Model(&models.ArtworkPage{}).
+		Select("'page' AS kind, id, artwork_id, file_path").
+		Scan(&pageReferences).Error
+	if err != nil {
+		return nil, err
+	}
+	references = append(references, pageReferences...)
+
+	var revisionReferences []FileReference
+	err = r.db.Model(&models.ArtworkRevision{}).
+		Select("'revision' AS kind, id, artwork_id, file_path").
+		Scan(&revisionReferences).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return append(references, revisionReferences...), nil
+}
+
+// metadataColumns lists the columns written when an artwork's descriptive metadata changes
+var metadataColumns = []string{"title", "description", "medium", "width_cm", "height_cm", "depth_cm", "creation_year", "tags", "updated_at"}
+
+// UpdateMetadata updates the descriptive metadata of an artwork
+func (r *ArtworkRepository) UpdateMetadata(artwork *models.Artwork) error {
+	return r.db.Model(artwork).Select(metadataColumns).Updates(artwork).Error
 }
 
 // Update updates artwork information
@@ -159,10 +1057,10 @@ func (r *ArtworkRepository) Update(artwork *models.Artwork) error {
 	return r.db.Save(artwork).Error
 }
 
-// Exists checks if an artwork exists
+// Exists checks if an artwork exists outside the trash
 func (r *ArtworkRepository) Exists(id uint) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.Artwork{}).Where("id = ?", id).Count(&count).Error
+	err := r.db.Model(&models.Artwork{}).Scopes(notDeleted).Where("id = ?", id).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
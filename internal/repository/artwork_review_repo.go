@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"art-collection-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ArtworkReviewRepository handles artwork review history data access operations
+type ArtworkReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewArtworkReviewRepository creates a new artwork review repository instance
+func NewArtworkReviewRepository(db *gorm.DB) *ArtworkReviewRepository {
+	return &ArtworkReviewRepository{db: db}
+}
+
+// GetByArtworkID retrieves the review timeline of an artwork, oldest first
+func (r *ArtworkReviewRepository) GetByArtworkID(artworkID uint) ([]models.ArtworkReview, error) {
+	var reviews []models.ArtworkReview
+	err := r.db.Preload("Reviewer").
+		Where("artwork_id = ?", artworkID).
+		Order("created_at ASC, id ASC").
+		Find(&reviews).Error
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// GetByReviewerID retrieves the review decisions made by a reviewer with pagination, newest first
+func (r *ArtworkReviewRepository) GetByReviewerID(reviewerID uint, page, pageSize int) ([]models.ArtworkReview, int64, error) {
+	var reviews []models.ArtworkReview
+	var total int64
+
+	// Count total reviews by this reviewer
+	if err := r.db.Model(&models.ArtworkReview{}).Where("reviewer_id = ?", reviewerID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+
+	// Retrieve paginated reviews with artwork information
+	err := r.db.Preload("Artwork").
+		Where("reviewer_id = ?", reviewerID).
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&reviews).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"art-collection-system/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlobRepository handles content-addressed file data access operations
+type BlobRepository struct {
+	db *gorm.DB
+}
+
+// NewBlobRepository creates a new blob repository instance
+func NewBlobRepository(db *gorm.DB) *BlobRepository {
+	return &BlobRepository{db: db}
+}
+
+// Acquire adds a reference to the blob with the given hash
+// It returns gorm.ErrRecordNotFound when no such blob exists, in which case the caller
+// stores the file and calls Create. The row lock keeps a concurrent Release from
+// deleting the file between the lookup and the increment.
+func (r *BlobRepository) Acquire(hash string) (*models.Blob, error) {
+	var blob models.Blob
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("hash = ?", hash).First(&blob).Error; err != nil {
+			return err
+		}
+		blob.RefCount++
+		return tx.Model(&blob).Select("ref_count", "updated_at").Updates(&blob).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &blob, nil
+}
+
+// Create records a newly stored blob holding one reference and reports whether it was created
+// It returns false when another upload of the same content created the blob first; the caller
+// then takes a reference to that blob with Acquire
+func (r *BlobRepository) Create(blob *models.Blob) (bool, error) {
+	blob.RefCount = 1
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hash"}},
+		DoNothing: true,
+	}).Create(blob)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Release removes a reference from a blob and reports whether it was the last one
+// When it was, onLast runs before the row is deleted and while it is still locked,
+// so the file is never deleted after a concurrent upload has taken a new reference
+func (r *BlobRepository) Release(id uint, onLast func(blob *models.Blob) error) (bool, error) {
+	last := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var blob models.Blob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&blob, id).Error; err != nil {
+			return err
+		}
+
+		blob.RefCount--
+		if blob.RefCount > 0 {
+			return tx.Model(&blob).Select("ref_count", "updated_at").Updates(&blob).Error
+		}
+
+		last = true
+		if err := onLast(&blob); err != nil {
+			return err
+		}
+		return tx.Delete(&blob).Error
+	})
+	return last, err
+}
+
+// GetByID retrieves a blob by ID
+func (r *BlobRepository) GetByID(id uint) (*models.Blob, error) {
+	var blob models.Blob
+	if err := r.db.First(&blob, id).Error; err != nil {
+		return nil, err
+	}
+	return &blob, nil
+}
+
+// BlobUsage is a blob with the number of artworks, pages and revisions actually referencing it
+type BlobUsage struct {
+	models.Blob
+	References int
+}
+
+// blobReferencesQuery counts the references to each blob held by artworks, their pages and previous revisions
+const blobReferencesQuery = `SELECT blob_id, COUNT(*) AS refs FROM (
+	SELECT blob_id FROM artworks WHERE blob_id IS NOT NULL
+	UNION ALL
+	SELECT blob_id FROM artwork_pages WHERE blob_id IS NOT NULL
+	UNION ALL
+	SELECT blob_id FROM artwork_revisions WHERE blob_id IS NOT NULL
+) AS blob_refs GROUP BY blob_id`
+
+// GetUsage retrieves all blobs with their actual reference counts
+func (r *BlobRepository) GetUsage() ([]BlobUsage, error) {
+	var usage []BlobUsage
+	err := r.db.Table("blobs").
+		Select("blobs.*, COALESCE(refs.refs, 0) AS `references`").
+		Joins("LEFT JOIN (" + blobReferencesQuery + ") AS refs ON refs.blob_id = blobs.id").
+		Order("blobs.id ASC").
+		Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// DeleteUnreferenced deletes a blob that no artwork, page or revision references and that has not
+// been acquired since updatedBefore, running onDelete while the row is locked
+// It reports whether the blob was deleted; a blob that gained a reference meanwhile is kept
+func (r *BlobRepository) DeleteUnreferenced(id uint, updatedBefore time.Time, onDelete func(blob *models.Blob) error) (bool, error) {
+	deleted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var blob models.Blob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&blob, id).Error; err != nil {
+			return err
+		}
+		// An upload acquiring the blob bumps updated_at before its artwork row exists
+		if !blob.UpdatedAt.Before(updatedBefore) {
+			return nil
+		}
+
+		var references int64
+		if err := tx.Model(&models.Artwork{}).Where("blob_id = ?", id).Count(&references).Error; err != nil {
+			return err
+		}
+		if references == 0 {
+			if err := tx.Model(&models.ArtworkPage{}).Where("blob_id = ?", id).Count(&references).Error; err != nil {
+				return err
+			}
+		}
+		if references == 0 {
+			if err := tx.Model(&models.ArtworkRevision{}).Where("blob_id = ?", id).Count(&references).Error; err != nil {
+				return err
+			}
+		}
+		if references > 0 {
+			return nil
+		}
+
+		if err := onDelete(&blob); err != nil {
+			return err
+		}
+		deleted = true
+		return tx.Delete(&blob).Error
+	})
+	return deleted, err
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"art-collection-system/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CommentRepository handles comment data access operations
+type CommentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository creates a new comment repository instance
+func NewCommentRepository(db *gorm.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+// Create creates a new comment
+func (r *CommentRepository) Create(comment *models.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+// GetByID retrieves a comment by ID
+func (r *CommentRepository) GetByID(id uint) (*models.Comment, error) {
+	var comment models.Comment
+	err := r.db.First(&comment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// GetThreadsByArtwork retrieves the comments starting threads on an artwork with pagination, newest first
+func (r *CommentRepository) GetThreadsByArtwork(artworkID uint, page, pageSize int) ([]models.Comment, int64, error) {
+	var comments []models.Comment
+	var total int64
+
+	// Count total threads
+	if err := r.db.Model(&models.Comment{}).Where("artwork_id = ? AND root_id IS NULL", artworkID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+
+	// Retrieve paginated threads with user information
+	err := r.db.Preload("User").
+		Where("artwork_id = ? AND root_id IS NULL", artworkID).
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&comments).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// GetReplies retrieves all replies in the given threads, oldest first
+func (r *CommentRepository) GetReplies(rootIDs []uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	if len(rootIDs) == 0 {
+		return comments, nil
+	}
+
+	err := r.db.Preload("User").
+		Where("root_id IN ?", rootIDs).
+		Order("created_at ASC, id ASC").
+		Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// List retrieves comments on all artworks with pagination, newest first
+// hidden filters on the hidden flag when not nil; artworkID filters on the artwork when not zero
+func (r *CommentRepository) List(hidden *bool, artworkID uint, page, pageSize int) ([]models.Comment, int64, error) {
+	var comments []models.Comment
+	var total int64
+
+	filter := func(db *gorm.DB) *gorm.DB {
+		if hidden != nil {
+			db = db.Where("is_hidden = ?", *hidden)
+		}
+		if artworkID != 0 {
+			db = db.Where("artwork_id = ?", artworkID)
+		}
+		return db
+	}
+
+	// Count total matching comments
+	if err := r.db.Model(&models.Comment{}).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+
+	// Retrieve paginated comments with user information
+	err := r.db.Preload("User").Scopes(filter).
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&comments).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// SetHidden hides or shows a comment, recording the admin who hid it
+func (r *CommentRepository) SetHidden(id uint, hidden bool, adminID uint) error {
+	fields := map[string]interface{}{
+		"is_hidden": hidden,
+		"hidden_by": nil,
+		"hidden_at": nil,
+	}
+	if hidden {
+		fields["hidden_by"] = adminID
+		fields["hidden_at"] = time.Now()
+	}
+	return r.db.Model(&models.Comment{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// DeleteIfNoReplies permanently deletes a comment within a transaction unless it has replies
+// The comment's row lock keeps replies from being added meanwhile. It reports false without
+// deleting anything when the comment has replies.
+func (r *CommentRepository) DeleteIfNoReplies(id uint) (bool, error) {
+	deleted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var comment models.Comment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&comment, id).Error; err != nil {
+			return err
+		}
+
+		var replies int64
+		if err := tx.Model(&models.Comment{}).Where("parent_id = ?", id).Count(&replies).Error; err != nil {
+			return err
+		}
+		if replies > 0 {
+			return nil
+		}
+
+		if err := tx.Delete(&comment).Error; err != nil {
+			return err
+		}
+		deleted = true
+		return nil
+	})
+	return deleted, err
+}
+
+// DeleteWithReplies permanently deletes a comment together with the replies below it
+// and returns the number of comments deleted
+func (r *CommentRepository) DeleteWithReplies(comment *models.Comment) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ids := []uint{comment.ID}
+
+		// Only a thread's replies can be below the comment; find them by following parent IDs
+		rootID := comment.ID
+		if comment.RootID != nil {
+			rootID = *comment.RootID
+		}
+		var replies []models.Comment
+		if err := tx.Select("id", "parent_id").Where("root_id = ?", rootID).Order("id ASC").Find(&replies).Error; err != nil {
+			return err
+		}
+
+		// A reply is always created after the comment it replies to, so in ID order a reply's
+		// parent has been seen before the reply
+		below := map[uint]bool{comment.ID: true}
+		for _, reply := range replies {
+			if reply.ParentID != nil && below[*reply.ParentID] {
+				below[reply.ID] = true
+				ids = append(ids, reply.ID)
+			}
+		}
+
+		result := tx.Where("id IN ?", ids).Delete(&models.Comment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return nil
+	})
+	return deleted, err
+}
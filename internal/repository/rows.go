@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+// eachRow runs a query and calls fn for every result row while the cursor is open
+// Used by exports to stream large result sets instead of loading them into memory
+func eachRow(query *gorm.DB, fn func(rows *sql.Rows) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
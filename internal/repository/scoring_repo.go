@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"art-collection-system/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ScoringRepository handles rubric, judge assignment and score data access operations
+type ScoringRepository struct {
+	db *gorm.DB
+}
+
+// NewScoringRepository creates a new scoring repository instance
+func NewScoringRepository(db *gorm.DB) *ScoringRepository {
+	return &ScoringRepository{db: db}
+}
+
+// GetCriteria retrieves the rubric criteria of an activity in display order
+func (r *ScoringRepository) GetCriteria(activityID uint) ([]models.RubricCriterion, error) {
+	var criteria []models.RubricCriterion
+	err := r.db.Where("activity_id = ?", activityID).Order("sort_order ASC, id ASC").Find(&criteria).Error
+	if err != nil {
+		return nil, err
+	}
+	return criteria, nil
+}
+
+// ReplaceCriteria replaces the rubric criteria of an activity within a transaction
+func (r *ScoringRepository) ReplaceCriteria(activityID uint, criteria []models.RubricCriterion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("activity_id = ?", activityID).Delete(&models.RubricCriterion{}).Error; err != nil {
+			return err
+		}
+		if len(criteria) == 0 {
+			return nil
+		}
+		return tx.Create(&criteria).Error
+	})
+}
+
+// CountScoresByActivity counts the scores recorded against an activity's rubric
+func (r *ScoringRepository) CountScoresByActivity(activityID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ArtworkScore{}).
+		Joins("JOIN rubric_criteria ON rubric_criteria.id = artwork_scores.criterion_id").
+		Where("rubric_criteria.activity_id = ?", activityID).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetJudges retrieves the judges assigned to an activity with user information
+func (r *ScoringRepository) GetJudges(activityID uint) ([]models.ActivityJudge, error) {
+	var judges []models.ActivityJudge
+	err := r.db.Preload("User").Where("activity_id = ?", activityID).Order("id ASC").Find(&judges).Error
+	if err != nil {
+		return nil, err
+	}
+	return judges, nil
+}
+
+// ReplaceJudges replaces the judges assigned to an activity within a transaction
+func (r *ScoringRepository) ReplaceJudges(activityID uint, userIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("activity_id = ?", activityID).Delete(&models.ActivityJudge{}).Error; err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		judges := make([]models.ActivityJudge, 0, len(userIDs))
+		for _, userID := range userIDs {
+			judges = append(judges, models.ActivityJudge{ActivityID: activityID, UserID: userID})
+		}
+		return tx.Create(&judges).Error
+	})
+}
+
+// IsJudge checks if a user is assigned as a judge of an activity
+func (r *ScoringRepository) IsJudge(activityID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ActivityJudge{}).
+		Where("activity_id = ? AND user_id = ?", activityID, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetJudgedActivityIDs retrieves the IDs of the activities a user is assigned to judge
+func (r *ScoringRepository) GetJudgedActivityIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.ActivityJudge{}).Where("user_id = ?", userID).Pluck("activity_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// UpsertScores creates or updates a judge's scores for an artwork in a single statement
+func (r *ScoringRepository) UpsertScores(scores []models.ArtworkScore) error {
+	if len(scores) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "artwork_id"}, {Name: "criterion_id"}, {Name: "judge_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"score", "updated_at"}),
+	}).Create(&scores).Error
+}
+
+// GetScoresByActivity retrieves all scores recorded against an activity's rubric
+func (r *ScoringRepository) GetScoresByActivity(activityID uint) ([]models.ArtworkScore, error) {
+	var scores []models.ArtworkScore
+	err := r.db.Joins("JOIN rubric_criteria ON rubric_criteria.id = artwork_scores.criterion_id").
+		Where("rubric_criteria.activity_id = ?", activityID).
+		Find(&scores).Error
+	if err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// GetScoresByJudge retrieves a judge's scores for an activity
+func (r *ScoringRepository) GetScoresByJudge(activityID, judgeID uint) ([]models.ArtworkScore, error) {
+	var scores []models.ArtworkScore
+	err := r.db.Joins("JOIN rubric_criteria ON rubric_criteria.id = artwork_scores.criterion_id").
+		Where("rubric_criteria.activity_id = ? AND artwork_scores.judge_id = ?", activityID, judgeID).
+		Find(&scores).Error
+	if err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"art-collection-system/internal/models"
+	"database/sql"
+
 	"gorm.io/gorm"
 )
 
@@ -86,10 +88,23 @@ func (r *UserRepository) List(page, pageSize int) ([]models.User, int64, error)
 	return users, total, nil
 }
 
-// CountArtworks counts the total number of artworks for a user
+// Stream calls fn for every user, newest first, reading them with a cursor
+func (r *UserRepository) Stream(fn func(user *models.User) error) error {
+	return eachRow(r.db.Model(&models.User{}).Order("created_at DESC"), func(rows *sql.Rows) error {
+		var user models.User
+		if err := r.db.ScanRows(rows, &user); err != nil {
+			return err
+		}
+		return fn(&user)
+	})
+}
+
+// CountArtworks counts the total number of submitted artworks for a user
 func (r *UserRepository) CountArtworks(userID uint) (int64, error) {
 	var count int64
-	err := r.db.Table("artworks").Where("user_id = ?", userID).Count(&count).Error
+	err := r.db.Table("artworks").
+		Where("user_id = ? AND review_status <> ? AND is_deleted = ?", userID, models.StatusDraft, false).
+		Count(&count).Error
 	if err != nil {
 		return 0, err
 	}
@@ -100,7 +115,7 @@ func (r *UserRepository) CountArtworks(userID uint) (int64, error) {
 func (r *UserRepository) CountArtworksByStatus(userID uint, status string) (int64, error) {
 	var count int64
 	err := r.db.Table("artworks").
-		Where("user_id = ? AND review_status = ?", userID, status).
+		Where("user_id = ? AND review_status = ? AND is_deleted = ?", userID, status, false).
 		Count(&count).Error
 	if err != nil {
 		return 0, err
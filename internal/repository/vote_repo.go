@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"art-collection-system/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VoteRepository handles vote data access operations
+type VoteRepository struct {
+	db *gorm.DB
+}
+
+// NewVoteRepository creates a new vote repository instance
+func NewVoteRepository(db *gorm.DB) *VoteRepository {
+	return &VoteRepository{db: db}
+}
+
+// Create records a vote and reports whether it was recorded
+// A vote by the same user for the same artwork is left as it is and reported as not recorded
+func (r *VoteRepository) Create(vote *models.Vote) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(vote)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete removes a user's vote for an artwork and reports whether there was one
+func (r *VoteRepository) Delete(artworkID, userID uint) (bool, error) {
+	result := r.db.Where("artwork_id = ? AND user_id = ?", artworkID, userID).Delete(&models.Vote{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// countedVotes limits a vote query to the votes for artworks that are not in the trash
+func countedVotes(db *gorm.DB) *gorm.DB {
+	return db.Joins("JOIN artworks ON artworks.id = votes.artwork_id AND artworks.is_deleted = ?", false)
+}
+
+// GetArtworkIDsByUser retrieves the IDs of the artworks a user voted for in an activity,
+// leaving out artworks in the trash
+func (r *VoteRepository) GetArtworkIDsByUser(activityID, userID uint) ([]uint, error) {
+	ids := make([]uint, 0)
+	err := r.db.Model(&models.Vote{}).Scopes(countedVotes).
+		Where("votes.activity_id = ? AND votes.user_id = ?", activityID, userID).
+		Order("votes.id ASC").
+		Pluck("votes.artwork_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CountByArtwork counts the votes for each artwork of an activity that has any,
+// leaving out artworks in the trash
+func (r *VoteRepository) CountByArtwork(activityID uint) (map[uint]int64, error) {
+	var rows []struct {
+		ArtworkID uint
+		Votes     int64
+	}
+	err := r.db.Model(&models.Vote{}).Scopes(countedVotes).
+		Select("votes.artwork_id, COUNT(*) AS votes").
+		Where("votes.activity_id = ?", activityID).
+		Group("votes.artwork_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ArtworkID] = row.Votes
+	}
+	return counts, nil
+}
+
+// HasVotes reports whether an artwork has any votes
+func (r *VoteRepository) HasVotes(artworkID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Vote{}).Where("artwork_id = ?", artworkID).Count(&count).Error
+	return count > 0, err
+}
+
+// SyncArtworkCounts stores the current number of votes of every artwork of an activity in artworks.vote_count
+func (r *VoteRepository) SyncArtworkCounts(activityID uint) error {
+	return r.db.Exec(`
+		UPDATE artworks
+		LEFT JOIN (
+			SELECT artwork_id, COUNT(*) AS votes FROM votes WHERE activity_id = ? GROUP BY artwork_id
+		) counted ON counted.artwork_id = artworks.id
+		SET artworks.vote_count = COALESCE(counted.votes, 0)
+		WHERE artworks.activity_id = ?`, activityID, activityID).Error
+}
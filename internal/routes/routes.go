@@ -16,6 +16,12 @@ func SetupRoutes(
 	activityHandler *handler.ActivityHandler,
 	artworkHandler *handler.ArtworkHandler,
 	adminHandler *handler.AdminHandler,
+	scoringHandler *handler.ScoringHandler,
+	galleryHandler *handler.GalleryHandler,
+	exportHandler *handler.ExportHandler,
+	uploadHandler *handler.UploadHandler,
+	voteHandler *handler.VoteHandler,
+	commentHandler *handler.CommentHandler,
 	authMiddleware gin.HandlerFunc,
 	adminMiddleware gin.HandlerFunc,
 	redisClient *redis.Client,
@@ -28,13 +34,13 @@ func SetupRoutes(
 	v1 := r.Group("/api/v1")
 
 	// Public routes (no authentication required)
-	setupPublicRoutes(v1, authHandler, activityHandler, redisClient)
+	setupPublicRoutes(v1, authHandler, activityHandler, artworkHandler, galleryHandler, uploadHandler, commentHandler, redisClient)
 
 	// Protected routes (authentication required)
-	setupProtectedRoutes(v1, authHandler, userHandler, activityHandler, artworkHandler, authMiddleware, redisClient)
+	setupProtectedRoutes(v1, authHandler, userHandler, activityHandler, artworkHandler, scoringHandler, uploadHandler, voteHandler, commentHandler, authMiddleware, redisClient)
 
 	// Admin routes (authentication + admin role required)
-	setupAdminRoutes(v1, activityHandler, adminHandler, authMiddleware, adminMiddleware)
+	setupAdminRoutes(v1, activityHandler, artworkHandler, adminHandler, scoringHandler, exportHandler, commentHandler, authMiddleware, adminMiddleware)
 }
 
 // setupPublicRoutes configures public routes
@@ -42,6 +48,10 @@ func setupPublicRoutes(
 	rg *gin.RouterGroup,
 	authHandler *handler.AuthHandler,
 	activityHandler *handler.ActivityHandler,
+	artworkHandler *handler.ArtworkHandler,
+	galleryHandler *handler.GalleryHandler,
+	uploadHandler *handler.UploadHandler,
+	commentHandler *handler.CommentHandler,
 	redisClient *redis.Client,
 ) {
 	// Authentication routes
@@ -57,7 +67,21 @@ func setupPublicRoutes(
 	{
 		activities.GET("", activityHandler.ListActivities)
 		activities.GET("/:id", activityHandler.GetActivity)
+
+		// Public gallery of approved artworks
+		activities.GET("/:id/gallery", galleryHandler.ListGallery)
+		activities.GET("/:id/gallery/:artworkId", galleryHandler.GetGalleryArtwork)
+		activities.GET("/:id/gallery/:artworkId/image", galleryHandler.ServeGalleryImage)
+		activities.GET("/:id/gallery/:artworkId/pages/:page/image", galleryHandler.ServeGalleryPageImage)
+		activities.GET("/:id/gallery/:artworkId/comments", commentHandler.GetGalleryComments)
 	}
+
+	// Artwork images through signed URLs, for <img> tags that cannot send an Authorization header
+	rg.GET("/artworks/:id/signed-image", artworkHandler.ServeSignedImage)
+	rg.GET("/artworks/:id/pages/:page/signed-image", artworkHandler.ServeSignedPageImage)
+
+	// Resumable upload capability discovery
+	rg.OPTIONS("/uploads", uploadHandler.GetUploadOptions)
 }
 
 // setupProtectedRoutes configures routes that require authentication
@@ -67,6 +91,10 @@ func setupProtectedRoutes(
 	userHandler *handler.UserHandler,
 	activityHandler *handler.ActivityHandler,
 	artworkHandler *handler.ArtworkHandler,
+	scoringHandler *handler.ScoringHandler,
+	uploadHandler *handler.UploadHandler,
+	voteHandler *handler.VoteHandler,
+	commentHandler *handler.CommentHandler,
 	authMiddleware gin.HandlerFunc,
 	redisClient *redis.Client,
 ) {
@@ -97,9 +125,52 @@ func setupProtectedRoutes(
 	artworks := protected.Group("/artworks")
 	{
 		artworks.POST("", middleware.UploadRateLimiter(redisClient), artworkHandler.UploadArtwork)
+		artworks.GET("/trash", artworkHandler.GetTrash)
+		artworks.PUT("/:id", artworkHandler.UpdateArtwork)
 		artworks.DELETE("/:id", artworkHandler.DeleteArtwork)
+		artworks.POST("/:id/restore", artworkHandler.RestoreArtwork)
 		artworks.GET("/:id", artworkHandler.GetArtwork)
 		artworks.GET("/:id/image", artworkHandler.ServeImage)
+		artworks.PUT("/:id/file", middleware.UploadRateLimiter(redisClient), artworkHandler.ReplaceArtworkFile)
+		artworks.POST("/:id/pages", middleware.UploadRateLimiter(redisClient), artworkHandler.AddArtworkPages)
+		artworks.PUT("/:id/pages/order", artworkHandler.ReorderArtworkPages)
+		artworks.PUT("/:id/pages/:page/file", middleware.UploadRateLimiter(redisClient), artworkHandler.ReplacePageFile)
+		artworks.POST("/:id/submit", artworkHandler.SubmitArtwork)
+		artworks.GET("/:id/pages/:page/image", artworkHandler.ServePageImage)
+		artworks.POST("/:id/vote", middleware.VoteRateLimiter(redisClient), voteHandler.Vote)
+		artworks.DELETE("/:id/vote", middleware.VoteRateLimiter(redisClient), voteHandler.Unvote)
+		artworks.GET("/:id/comments", commentHandler.GetComments)
+		artworks.POST("/:id/comments", middleware.CommentRateLimiter(redisClient), commentHandler.AddComment)
+	}
+
+	// Comment routes (own comments, or any comment for admins)
+	comments := protected.Group("/comments")
+	{
+		comments.DELETE("/:id", commentHandler.DeleteComment)
+	}
+
+	// Public voting in activities
+	activities := protected.Group("/activities")
+	{
+		activities.GET("/:id/votes/mine", voteHandler.GetMyVotes)
+	}
+
+	// Resumable artwork uploads (tus protocol)
+	uploads := protected.Group("/uploads")
+	{
+		uploads.POST("", middleware.UploadRateLimiter(redisClient), uploadHandler.CreateUpload)
+		uploads.HEAD("/:id", uploadHandler.GetUploadOffset)
+		uploads.PATCH("/:id", uploadHandler.PatchUpload)
+		uploads.DELETE("/:id", uploadHandler.DeleteUpload)
+	}
+
+	// Judging routes (assigned judges only, checked in the scoring service)
+	judging := protected.Group("/judging")
+	{
+		judging.GET("/activities", scoringHandler.GetJudgedActivities)
+		judging.GET("/activities/:id/artworks", scoringHandler.GetJudgingSheet)
+		judging.GET("/activities/:id/results", scoringHandler.GetResults)
+		judging.PUT("/artworks/:id/scores", scoringHandler.SubmitScores)
 	}
 }
 
@@ -107,7 +178,11 @@ func setupProtectedRoutes(
 func setupAdminRoutes(
 	rg *gin.RouterGroup,
 	activityHandler *handler.ActivityHandler,
+	artworkHandler *handler.ArtworkHandler,
 	adminHandler *handler.AdminHandler,
+	scoringHandler *handler.ScoringHandler,
+	exportHandler *handler.ExportHandler,
+	commentHandler *handler.CommentHandler,
 	authMiddleware gin.HandlerFunc,
 	adminMiddleware gin.HandlerFunc,
 ) {
@@ -121,21 +196,53 @@ func setupAdminRoutes(
 		activities.PUT("/:id", activityHandler.UpdateActivity)
 		activities.DELETE("/:id", activityHandler.DeleteActivity)
 		activities.GET("/:id/artworks", adminHandler.GetActivityArtworks)
+		activities.GET("/:id/artworks/export", exportHandler.ExportActivityArtworks)
+		activities.GET("/:id/rubric", scoringHandler.GetRubric)
+		activities.PUT("/:id/rubric", scoringHandler.SetRubric)
+		activities.GET("/:id/judges", scoringHandler.GetJudges)
+		activities.PUT("/:id/judges", scoringHandler.SetJudges)
+		activities.PUT("/:id/scoring", scoringHandler.SetScoringStatus)
+		activities.GET("/:id/results", scoringHandler.GetResults)
+		activities.GET("/:id/export.zip", exportHandler.ExportActivityZip)
 	}
 
 	// Artwork review
 	admin.GET("/review-queue", adminHandler.GetReviewQueue)
+	admin.GET("/review-queue/export", exportHandler.ExportReviewQueue)
+	admin.POST("/review-queue/claim", adminHandler.ClaimReviewQueue)
+	admin.GET("/review-queue/claims", adminHandler.GetClaimedArtworks)
+	admin.POST("/review-queue/release", adminHandler.ReleaseReviewQueue)
+	admin.GET("/reject-reasons", adminHandler.GetRejectReasons)
 	artworks := admin.Group("/artworks")
 	{
 		artworks.PUT("/:id/review", adminHandler.ReviewArtwork)
 		artworks.PUT("/batch-review", adminHandler.BatchReviewArtworks)
+		artworks.GET("/trash", artworkHandler.GetAllTrash)
+		artworks.GET("/:id/reviews", adminHandler.GetArtworkReviews)
+		artworks.GET("/:id/original-metadata", adminHandler.GetOriginalMetadata)
+		artworks.GET("/:id/duplicates", adminHandler.GetPossibleDuplicates)
+		artworks.GET("/:id/revisions", artworkHandler.GetArtworkRevisions)
+		artworks.GET("/:id/revisions/:revision/image", artworkHandler.ServeRevisionImage)
 	}
+	admin.GET("/reviewers/:id/reviews", adminHandler.GetReviewerActivity)
 
 	// User management
 	users := admin.Group("/users")
 	{
 		users.GET("", adminHandler.ListUsers)
+		users.GET("/export", exportHandler.ExportUsers)
 		users.PUT("/:id/role", adminHandler.UpdateUserRole)
 		users.GET("/:id/statistics", adminHandler.GetUserStatistics)
 	}
+
+	// Comment moderation
+	comments := admin.Group("/comments")
+	{
+		comments.GET("", commentHandler.ListComments)
+		comments.PUT("/:id/visibility", commentHandler.SetCommentVisibility)
+		comments.DELETE("/:id", commentHandler.DeleteComment)
+	}
+
+	// Storage maintenance
+	admin.GET("/storage/check", adminHandler.CheckStorage)
 }
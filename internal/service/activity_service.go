@@ -4,6 +4,9 @@ import (
 	"art-collection-system/internal/models"
 	"art-collection-system/internal/repository"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -17,9 +20,144 @@ func NewActivityService(repo *repository.ActivityRepository) *ActivityService {
 	return &ActivityService{repo: repo}
 }
 
+// ErrInvalidImageConstraints is returned when an activity's image constraints are inconsistent
+var ErrInvalidImageConstraints = errors.New("图片限制设置无效")
+
+// ErrInvalidVotingSettings is returned when an activity's voting settings are inconsistent
+var ErrInvalidVotingSettings = errors.New("投票设置无效")
+
+// ActivitySettings holds the optional per-activity settings beyond the basic fields
+type ActivitySettings struct {
+	GalleryEnabled   bool
+	GalleryOpensAt   *time.Time
+	ImageConstraints models.ImageConstraints
+	VotingEnabled    bool
+	VotingOpensAt    *time.Time
+	VotingClosesAt   *time.Time
+	MaxVotesPerUser  int // 0 allows a vote for every artwork
+}
+
+// ActivitySettingsUpdate holds the changes to an activity's optional settings
+// Nil fields keep their current value; a time field pointing to nil clears that time
+type ActivitySettingsUpdate struct {
+	GalleryEnabled   *bool
+	GalleryOpensAt   **time.Time
+	ImageConstraints *models.ImageConstraints // replaces all current constraints when set
+	VotingEnabled    *bool
+	VotingOpensAt    **time.Time
+	VotingClosesAt   **time.Time
+	MaxVotesPerUser  *int
+}
+
+// settingsOf returns an activity's current optional settings
+func settingsOf(activity *models.Activity) ActivitySettings {
+	return ActivitySettings{
+		GalleryEnabled:   activity.GalleryEnabled,
+		GalleryOpensAt:   activity.GalleryOpensAt,
+		ImageConstraints: activity.ImageConstraints,
+		VotingEnabled:    activity.VotingEnabled,
+		VotingOpensAt:    activity.VotingOpensAt,
+		VotingClosesAt:   activity.VotingClosesAt,
+		MaxVotesPerUser:  activity.MaxVotesPerUser,
+	}
+}
+
+// merge returns the settings with the changes in the update applied
+func (u ActivitySettingsUpdate) merge(settings ActivitySettings) ActivitySettings {
+	if u.GalleryEnabled != nil {
+		settings.GalleryEnabled = *u.GalleryEnabled
+	}
+	if u.GalleryOpensAt != nil {
+		settings.GalleryOpensAt = *u.GalleryOpensAt
+	}
+	if u.ImageConstraints != nil {
+		settings.ImageConstraints = *u.ImageConstraints
+	}
+	if u.VotingEnabled != nil {
+		settings.VotingEnabled = *u.VotingEnabled
+	}
+	if u.VotingOpensAt != nil {
+		settings.VotingOpensAt = *u.VotingOpensAt
+	}
+	if u.VotingClosesAt != nil {
+		settings.VotingClosesAt = *u.VotingClosesAt
+	}
+	if u.MaxVotesPerUser != nil {
+		settings.MaxVotesPerUser = *u.MaxVotesPerUser
+	}
+	return settings
+}
+
+// applySettings validates the optional settings and copies them onto an activity
+func applySettings(activity *models.Activity, settings ActivitySettings) error {
+	constraints, err := normalizeImageConstraints(settings.ImageConstraints)
+	if err != nil {
+		return err
+	}
+	if settings.MaxVotesPerUser < 0 {
+		return fmt.Errorf("%w：每人投票数不能为负数", ErrInvalidVotingSettings)
+	}
+	if settings.VotingOpensAt != nil && settings.VotingClosesAt != nil && !settings.VotingClosesAt.After(*settings.VotingOpensAt) {
+		return fmt.Errorf("%w：投票结束时间必须晚于开始时间", ErrInvalidVotingSettings)
+	}
+
+	activity.GalleryEnabled = settings.GalleryEnabled
+	activity.GalleryOpensAt = settings.GalleryOpensAt
+	activity.ImageConstraints = constraints
+	activity.VotingEnabled = settings.VotingEnabled
+	activity.VotingOpensAt = settings.VotingOpensAt
+	activity.VotingClosesAt = settings.VotingClosesAt
+	activity.MaxVotesPerUser = settings.MaxVotesPerUser
+	return nil
+}
+
+// normalizeImageConstraints validates image constraints and normalizes the allowed format names
+func normalizeImageConstraints(c models.ImageConstraints) (models.ImageConstraints, error) {
+	invalid := func(message string) (models.ImageConstraints, error) {
+		return c, fmt.Errorf("%w：%s", ErrInvalidImageConstraints, message)
+	}
+
+	formats := make([]string, 0, len(c.AllowedFormats))
+	for _, format := range c.AllowedFormats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format == "jpg" {
+			format = "jpeg"
+		}
+		if !slices.Contains(models.ImageFormats, format) {
+			return invalid(fmt.Sprintf("不支持的图片格式 %q，可选值为 %s", format, strings.Join(models.ImageFormats, "、")))
+		}
+		if !slices.Contains(formats, format) {
+			formats = append(formats, format)
+		}
+	}
+	if len(formats) == 0 {
+		formats = nil
+	}
+	c.AllowedFormats = formats
+
+	if c.MinBytes < 0 || c.MaxBytes < 0 || c.MinWidth < 0 || c.MaxWidth < 0 ||
+		c.MinHeight < 0 || c.MaxHeight < 0 || c.MinAspectRatio < 0 || c.MaxAspectRatio < 0 {
+		return invalid("限制值不能为负数")
+	}
+	if c.MaxBytes > 0 && c.MinBytes > c.MaxBytes {
+		return invalid("最小文件大小不能大于最大文件大小")
+	}
+	if c.MaxWidth > 0 && c.MinWidth > c.MaxWidth {
+		return invalid("最小宽度不能大于最大宽度")
+	}
+	if c.MaxHeight > 0 && c.MinHeight > c.MaxHeight {
+		return invalid("最小高度不能大于最大高度")
+	}
+	if c.MaxAspectRatio > 0 && c.MinAspectRatio > c.MaxAspectRatio {
+		return invalid("最小宽高比不能大于最大宽高比")
+	}
+
+	return c, nil
+}
+
 // CreateActivity creates a new activity
 // Requirements: 3.1
-func (s *ActivityService) CreateActivity(name, description string, deadline *time.Time, maxUploads int) (*models.Activity, error) {
+func (s *ActivityService) CreateActivity(name, description string, deadline *time.Time, maxUploads int, settings ActivitySettings) (*models.Activity, error) {
 	if name == "" {
 		return nil, errors.New("activity name is required")
 	}
@@ -35,6 +173,9 @@ func (s *ActivityService) CreateActivity(name, description string, deadline *tim
 		MaxUploadsPerUser: maxUploads,
 		IsDeleted:         false,
 	}
+	if err := applySettings(activity, settings); err != nil {
+		return nil, err
+	}
 
 	if err := s.repo.Create(activity); err != nil {
 		return nil, err
@@ -44,8 +185,9 @@ func (s *ActivityService) CreateActivity(name, description string, deadline *tim
 }
 
 // UpdateActivity updates an existing activity
+// Only the optional settings present in the update are changed
 // Requirements: 3.2
-func (s *ActivityService) UpdateActivity(id uint, name, description string, deadline *time.Time, maxUploads int) error {
+func (s *ActivityService) UpdateActivity(id uint, name, description string, deadline *time.Time, maxUploads int, update ActivitySettingsUpdate) error {
 	// Check if activity exists
 	activity, err := s.repo.GetByID(id)
 	if err != nil {
@@ -61,6 +203,9 @@ func (s *ActivityService) UpdateActivity(id uint, name, description string, dead
 	if maxUploads > 0 {
 		activity.MaxUploadsPerUser = maxUploads
 	}
+	if err := applySettings(activity, update.merge(settingsOf(activity))); err != nil {
+		return err
+	}
 
 	return s.repo.Update(activity)
 }
@@ -80,6 +225,20 @@ func (s *ActivityService) DeleteActivity(id uint) error {
 	return s.repo.SoftDelete(id)
 }
 
+// SetScoringClosed opens or closes rubric scoring for an activity
+func (s *ActivityService) SetScoringClosed(id uint, closed bool) error {
+	// Check if activity exists
+	exists, err := s.repo.Exists(id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("activity not found")
+	}
+
+	return s.repo.UpdateFields(id, map[string]interface{}{"scoring_closed": closed})
+}
+
 // GetActivityByID retrieves an activity by ID
 // Requirements: 3.4
 func (s *ActivityService) GetActivityByID(id uint) (*models.Activity, error) {
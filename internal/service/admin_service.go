@@ -91,6 +91,12 @@ func (s *AdminService) GetUserStatistics(userID uint) (map[string]interface{}, e
 		return nil, errors.New("获取待审核作品统计失败")
 	}
 
+	// Count rejected artworks
+	rejectedArtworks, err := s.userRepo.CountArtworksByStatus(userID, string(models.StatusRejected))
+	if err != nil {
+		return nil, errors.New("获取已驳回作品统计失败")
+	}
+
 	// Build statistics response
 	statistics := map[string]interface{}{
 		"user_id":           user.ID,
@@ -100,6 +106,7 @@ func (s *AdminService) GetUserStatistics(userID uint) (map[string]interface{}, e
 		"total_artworks":    totalArtworks,
 		"approved_artworks": approvedArtworks,
 		"pending_artworks":  pendingArtworks,
+		"rejected_artworks": rejectedArtworks,
 		"created_at":        user.CreatedAt,
 	}
 
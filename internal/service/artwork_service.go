@@ -3,31 +3,347 @@ package service
 import (
 	"art-collection-system/internal/models"
 	"art-collection-system/internal/repository"
+	"art-collection-system/internal/utils"
+	"bytes"
 	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
 	"mime/multipart"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// Duplicate upload policies for files identical to another artwork's file in the same activity
+const (
+	DuplicatePolicyReject = "reject"
+	DuplicatePolicyFlag   = "flag"
+)
+
+// maxArtworkPages is the maximum number of images a multi-page artwork may consist of
+const maxArtworkPages = 50
+
+const (
+	// nearDuplicateMaxDistance is the largest dHash Hamming distance still reported as a possible duplicate
+	nearDuplicateMaxDistance = 10
+
+	// maxDuplicateMatches is the maximum number of possible duplicates reported per artwork
+	maxDuplicateMatches = 10
 )
 
 // ArtworkService handles business logic for artworks
 type ArtworkService struct {
 	repo            *repository.ArtworkRepository
+	reviewRepo      *repository.ArtworkReviewRepository
 	activityService *ActivityService
 	fileService     *FileService
+	voteService     *VoteService
+	duplicatePolicy string
+	trashRetention  time.Duration
 }
 
 // NewArtworkService creates a new artwork service instance
-func NewArtworkService(repo *repository.ArtworkRepository, activityService *ActivityService, fileService *FileService) *ArtworkService {
+// trashRetention is how long deleted artworks stay in the trash before they are purged
+func NewArtworkService(repo *repository.ArtworkRepository, reviewRepo *repository.ArtworkReviewRepository, activityService *ActivityService, fileService *FileService, voteService *VoteService, duplicatePolicy string, trashRetention time.Duration) *ArtworkService {
 	return &ArtworkService{
 		repo:            repo,
+		reviewRepo:      reviewRepo,
 		activityService: activityService,
 		fileService:     fileService,
+		voteService:     voteService,
+		duplicatePolicy: duplicatePolicy,
+		trashRetention:  trashRetention,
+	}
+}
+
+// UploadedFile is an image file uploaded as one page of an artwork
+type UploadedFile struct {
+	File     multipart.File
+	Filename string
+}
+
+// ArtworkMetadata holds the descriptive information an author provides for an artwork
+type ArtworkMetadata struct {
+	Title        string
+	Description  string
+	Medium       string
+	WidthCM      *float64
+	HeightCM     *float64
+	DepthCM      *float64
+	CreationYear *int
+	Tags         []string
+}
+
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 2000
+	maxMediumLength      = 100
+	maxTagLength         = 30
+	maxTagCount          = 10
+	maxDimensionCM       = 10000
+	minCreationYear      = 1900
+)
+
+// normalizeMetadata trims and validates artwork metadata
+// An empty title falls back to the file name without its extension
+func normalizeMetadata(metadata *ArtworkMetadata, fileName string) error {
+	metadata.Title = strings.TrimSpace(metadata.Title)
+	if metadata.Title == "" {
+		metadata.Title = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	}
+	if utf8.RuneCountInString(metadata.Title) > maxTitleLength {
+		return fmt.Errorf("作品标题不能超过%d个字符", maxTitleLength)
+	}
+
+	metadata.Description = strings.TrimSpace(metadata.Description)
+	if utf8.RuneCountInString(metadata.Description) > maxDescriptionLength {
+		return fmt.Errorf("作品描述不能超过%d个字符", maxDescriptionLength)
+	}
+
+	metadata.Medium = strings.TrimSpace(metadata.Medium)
+	if utf8.RuneCountInString(metadata.Medium) > maxMediumLength {
+		return fmt.Errorf("创作媒介不能超过%d个字符", maxMediumLength)
+	}
+
+	for _, dimension := range []*float64{metadata.WidthCM, metadata.HeightCM, metadata.DepthCM} {
+		if dimension != nil && (*dimension <= 0 || *dimension > maxDimensionCM) {
+			return fmt.Errorf("作品尺寸必须在0到%d厘米之间", maxDimensionCM)
+		}
+	}
+
+	if metadata.CreationYear != nil {
+		currentYear := time.Now().Year()
+		if *metadata.CreationYear < minCreationYear || *metadata.CreationYear > currentYear {
+			return fmt.Errorf("创作年份必须在%d到%d之间", minCreationYear, currentYear)
+		}
+	}
+
+	// Trim tags and drop empty or duplicate (case-insensitive) ones
+	seen := make(map[string]bool, len(metadata.Tags))
+	tags := make([]string, 0, len(metadata.Tags))
+	for _, tag := range metadata.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return fmt.Errorf("标签\"%s\"不能超过%d个字符", tag, maxTagLength)
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTagCount {
+		return fmt.Errorf("标签数量不能超过%d个", maxTagCount)
+	}
+	metadata.Tags = tags
+
+	return nil
+}
+
+// applyMetadata copies normalized metadata onto an artwork
+func applyMetadata(artwork *models.Artwork, metadata ArtworkMetadata) {
+	artwork.Title = metadata.Title
+	artwork.Description = metadata.Description
+	artwork.Medium = metadata.Medium
+	artwork.WidthCM = metadata.WidthCM
+	artwork.HeightCM = metadata.HeightCM
+	artwork.DepthCM = metadata.DepthCM
+	artwork.CreationYear = metadata.CreationYear
+	artwork.Tags = metadata.Tags
+}
+
+// PrecheckUpload runs the checks of UploadArtwork that do not need the file
+// Resumable uploads call it before accepting any data, so a doomed upload is refused up front
+func (s *ArtworkService) PrecheckUpload(userID, activityID uint, filename string, metadata ArtworkMetadata, draft bool) error {
+	if err := normalizeMetadata(&metadata, filename); err != nil {
+		return err
+	}
+	return s.checkCanUpload(userID, activityID, draft)
+}
+
+// checkCanUpload checks that an activity accepts uploads and the user has not reached its upload limit
+// Drafts do not count toward the limit, so it is only checked when the draft is submitted
+func (s *ArtworkService) checkCanUpload(userID, activityID uint, draft bool) error {
+	isActive, err := s.activityService.IsActivityActive(activityID)
+	if err != nil {
+		return err
+	}
+	if !isActive {
+		return errors.New("活动不存在或已过期")
+	}
+	if draft {
+		return nil
+	}
+
+	canUpload, err := s.CheckUploadLimit(userID, activityID)
+	if err != nil {
+		return err
+	}
+	if !canUpload {
+		return errors.New("超过了该活动的上传数量限制")
 	}
+	return nil
 }
 
 // UploadArtwork handles artwork upload with validation
+// Several files make a multi-page artwork, with the files as its pages in the given order;
+// it still counts as a single upload against the activity's limit
+// A draft is kept out of the review queue and the upload limit until the author submits it
 // Requirements: 4.1, 4.2, 4.3, 4.4, 5.1
-func (s *ArtworkService) UploadArtwork(userID, activityID uint, file multipart.File, filename string) (*models.Artwork, error) {
-	// Validate activity is active
-	isActive, err := s.activityService.IsActivityActive(activityID)
+func (s *ArtworkService) UploadArtwork(userID, activityID uint, files []UploadedFile, metadata ArtworkMetadata, draft bool) (*models.Artwork, error) {
+	if len(files) == 0 {
+		return nil, errors.New("请上传文件")
+	}
+	if len(files) > maxArtworkPages {
+		return nil, fmt.Errorf("作品页数不能超过%d页", maxArtworkPages)
+	}
+
+	// Validate metadata before touching storage
+	if err := normalizeMetadata(&metadata, files[0].Filename); err != nil {
+		return nil, err
+	}
+
+	// Validate activity is active and the upload limit is not reached
+	if err := s.checkCanUpload(userID, activityID, draft); err != nil {
+		return nil, err
+	}
+
+	// Enforce the activity's image constraints on every page
+	for _, file := range files {
+		if err := s.checkActivityImageConstraints(activityID, file.File); err != nil {
+			return nil, err
+		}
+	}
+
+	// Save files
+	stored, err := s.saveFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject or flag an exact duplicate of another artwork in the activity
+	duplicateOfID, err := s.checkDuplicate(activityID, stored[0].ContentHash, 0)
+	if err != nil {
+		s.releaseStoredFiles(stored)
+		return nil, err
+	}
+
+	// Create artwork record with pending or draft status; the first file is page 1
+	artwork := &models.Artwork{
+		ActivityID:       activityID,
+		UserID:           userID,
+		BlobID:           &stored[0].BlobID,
+		FilePath:         stored[0].Path,
+		FileName:         files[0].Filename,
+		PageCount:        len(files),
+		Revision:         1,
+		OriginalMetadata: stored[0].OriginalMetadata,
+		ContentHash:      stored[0].ContentHash,
+		PerceptualHash:   stored[0].PerceptualHash,
+		DuplicateOfID:    duplicateOfID,
+		ReviewStatus:     models.StatusPending,
+	}
+	artwork.Pages = newArtworkPages(files[1:], stored[1:])
+	for i := range artwork.Pages {
+		artwork.Pages[i].Page = i + 2
+	}
+	applyMetadata(artwork, metadata)
+
+	if draft {
+		artwork.ReviewStatus = models.StatusDraft
+		if err := s.repo.Create(artwork); err != nil {
+			s.releaseStoredFiles(stored)
+			return nil, err
+		}
+		return artwork, nil
+	}
+
+	// Check the upload limit again while creating the record, as concurrent uploads
+	// by the same user may all have passed the check above
+	activity, err := s.activityService.GetActivityByID(activityID)
+	if err != nil {
+		s.releaseStoredFiles(stored)
+		return nil, err
+	}
+	created, err := s.repo.CreateWithinLimit(artwork, activity.MaxUploadsPerUser)
+	if err != nil || !created {
+		// If database creation fails, drop the references to the uploaded files
+		s.releaseStoredFiles(stored)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New("超过了该活动的上传数量限制")
+	}
+
+	return artwork, nil
+}
+
+// saveFiles saves uploaded files in order
+// If one fails, the files already saved are released
+func (s *ArtworkService) saveFiles(files []UploadedFile) ([]*StoredFile, error) {
+	stored := make([]*StoredFile, 0, len(files))
+	for _, file := range files {
+		saved, err := s.fileService.SaveFile(file.File, file.Filename)
+		if err != nil {
+			s.releaseStoredFiles(stored)
+			return nil, err
+		}
+		stored = append(stored, saved)
+	}
+	return stored, nil
+}
+
+// releaseStoredFiles drops the references to files saved for a record that was not created
+func (s *ArtworkService) releaseStoredFiles(stored []*StoredFile) {
+	for _, file := range stored {
+		_ = s.fileService.ReleaseFile(&file.BlobID, file.Path)
+	}
+}
+
+// newArtworkPages builds page records for saved files; the caller numbers them
+func newArtworkPages(files []UploadedFile, stored []*StoredFile) []models.ArtworkPage {
+	pages := make([]models.ArtworkPage, len(files))
+	for i := range files {
+		pages[i] = models.ArtworkPage{
+			BlobID:           &stored[i].BlobID,
+			FilePath:         stored[i].Path,
+			FileName:         files[i].Filename,
+			OriginalMetadata: stored[i].OriginalMetadata,
+			ContentHash:      stored[i].ContentHash,
+			PerceptualHash:   stored[i].PerceptualHash,
+		}
+	}
+	return pages
+}
+
+// AddArtworkPages appends pages to an artwork (author only)
+// The artwork goes back to pending review, since it has to be reviewed as a whole
+func (s *ArtworkService) AddArtworkPages(artworkID, userID uint, files []UploadedFile) (*models.Artwork, error) {
+	if len(files) == 0 {
+		return nil, errors.New("请上传文件")
+	}
+
+	artwork, err := s.repo.GetByID(artworkID)
+	if err != nil {
+		return nil, errors.New("作品不存在")
+	}
+
+	// Only the author can add pages
+	if artwork.UserID != userID {
+		return nil, errors.New("权限不足，只能修改自己的作品")
+	}
+	if artwork.PageCount+len(files) > maxArtworkPages {
+		return nil, fmt.Errorf("作品页数不能超过%d页", maxArtworkPages)
+	}
+
+	// Validate activity is still accepting submissions
+	isActive, err := s.activityService.IsActivityActive(artwork.ActivityID)
 	if err != nil {
 		return nil, err
 	}
@@ -35,39 +351,415 @@ func (s *ArtworkService) UploadArtwork(userID, activityID uint, file multipart.F
 		return nil, errors.New("活动不存在或已过期")
 	}
 
-	// Check upload limit
-	canUpload, err := s.CheckUploadLimit(userID, activityID)
+	// Enforce the activity's image constraints on every page
+	for _, file := range files {
+		if err := s.checkActivityImageConstraints(artwork.ActivityID, file.File); err != nil {
+			return nil, err
+		}
+	}
+
+	stored, err := s.saveFiles(files)
 	if err != nil {
 		return nil, err
 	}
-	if !canUpload {
-		return nil, errors.New("超过了该活动的上传数量限制")
+
+	// The page count is checked again under the artwork's row lock
+	updated, added, err := s.repo.AddPages(artworkID, userID, newArtworkPages(files, stored), maxArtworkPages)
+	if err != nil || !added {
+		s.releaseStoredFiles(stored)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("作品页数不能超过%d页", maxArtworkPages)
+	}
+
+	return updated, nil
+}
+
+// ReorderArtworkPages changes the order of an artwork's pages (author only)
+// order lists every current page number once, in the new reading order
+func (s *ArtworkService) ReorderArtworkPages(artworkID, userID uint, order []int) (*models.Artwork, error) {
+	artwork, err := s.repo.GetByID(artworkID)
+	if err != nil {
+		return nil, errors.New("作品不存在")
 	}
 
-	// Save file
-	filePath, err := s.fileService.SaveFile(file, filename)
+	// Only the author can reorder pages
+	if artwork.UserID != userID {
+		return nil, errors.New("权限不足，只能修改自己的作品")
+	}
+
+	// Validate activity is still accepting submissions
+	isActive, err := s.activityService.IsActivityActive(artwork.ActivityID)
 	if err != nil {
 		return nil, err
 	}
+	if !isActive {
+		return nil, errors.New("活动不存在或已过期")
+	}
 
-	// Create artwork record with pending status
-	artwork := &models.Artwork{
-		ActivityID:   activityID,
-		UserID:       userID,
-		FilePath:     filePath,
-		FileName:     filename,
-		ReviewStatus: models.StatusPending,
+	if !isPageOrder(order, artwork.PageCount) {
+		return nil, fmt.Errorf("页面顺序必须包含第1页到第%d页，且每页只出现一次", artwork.PageCount)
 	}
 
-	if err := s.repo.Create(artwork); err != nil {
-		// If database creation fails, try to delete the uploaded file
-		_ = s.fileService.DeleteFile(filePath)
+	updated, reordered, err := s.repo.ReorderPages(artworkID, order)
+	if err != nil {
+		return nil, err
+	}
+	if !reordered {
+		return nil, errors.New("作品页面已变化，请刷新后重试")
+	}
+
+	return updated, nil
+}
+
+// isPageOrder reports whether order is a permutation of the page numbers 1 to pageCount
+func isPageOrder(order []int, pageCount int) bool {
+	if len(order) != pageCount {
+		return false
+	}
+	seen := make([]bool, pageCount+1)
+	for _, page := range order {
+		if page < 1 || page > pageCount || seen[page] {
+			return false
+		}
+		seen[page] = true
+	}
+	return true
+}
+
+// GetArtworkPageFilePath retrieves the stored file path of a page of an artwork without permission checks
+// Page 1 is the artwork's own file
+func (s *ArtworkService) GetArtworkPageFilePath(artwork *models.Artwork, page int) (string, error) {
+	if page == 1 {
+		return artwork.FilePath, nil
+	}
+	if page < 1 || page > artwork.PageCount {
+		return "", errors.New("作品页面不存在")
+	}
+
+	artworkPage, err := s.repo.GetPage(artwork.ID, page)
+	if err != nil {
+		return "", errors.New("作品页面不存在")
+	}
+	return artworkPage.FilePath, nil
+}
+
+// UpdateArtworkMetadata replaces the descriptive metadata of an artwork (author only)
+func (s *ArtworkService) UpdateArtworkMetadata(artworkID, userID uint, metadata ArtworkMetadata) (*models.Artwork, error) {
+	artwork, err := s.repo.GetByID(artworkID)
+	if err != nil {
+		return nil, errors.New("作品不存在")
+	}
+
+	// Only the author can edit the metadata
+	if artwork.UserID != userID {
+		return nil, errors.New("权限不足，只能修改自己的作品")
+	}
+
+	if err := normalizeMetadata(&metadata, artwork.FileName); err != nil {
+		return nil, err
+	}
+
+	applyMetadata(artwork, metadata)
+	if err := s.repo.UpdateMetadata(artwork); err != nil {
 		return nil, err
 	}
 
 	return artwork, nil
 }
 
+// ReplaceArtworkFile stores a new revision of the file of a page of an artwork while keeping the artwork identity
+// Page 1 is the artwork's own file
+// The previous file is kept as a revision and the artwork goes back to pending review
+func (s *ArtworkService) ReplaceArtworkFile(artworkID, userID uint, page int, file multipart.File, filename string) (*models.Artwork, error) {
+	artwork, err := s.repo.GetByID(artworkID)
+	if err != nil {
+		return nil, errors.New("作品不存在")
+	}
+
+	// Only the author can replace the file
+	if artwork.UserID != userID {
+		return nil, errors.New("权限不足，只能修改自己的作品")
+	}
+	if page < 1 || page > artwork.PageCount {
+		return nil, errors.New("作品页面不存在")
+	}
+
+	// Validate activity is still accepting submissions
+	isActive, err := s.activityService.IsActivityActive(artwork.ActivityID)
+	if err != nil {
+		return nil, err
+	}
+	if !isActive {
+		return nil, errors.New("活动不存在或已过期")
+	}
+
+	// Enforce the activity's image constraints
+	if err := s.checkActivityImageConstraints(artwork.ActivityID, file); err != nil {
+		return nil, err
+	}
+
+	// Save the new file
+	stored, err := s.fileService.SaveFile(file, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject or flag an exact duplicate of another artwork in the activity; like added pages,
+	// only the first page is checked
+	var duplicateOfID *uint
+	if page == 1 {
+		duplicateOfID, err = s.checkDuplicate(artwork.ActivityID, stored.ContentHash, artworkID)
+		if err != nil {
+			_ = s.fileService.ReleaseFile(&stored.BlobID, stored.Path)
+			return nil, err
+		}
+	}
+
+	replacement := repository.FileReplacement{
+		BlobID:           &stored.BlobID,
+		FilePath:         stored.Path,
+		FileName:         filename,
+		OriginalMetadata: stored.OriginalMetadata,
+		ContentHash:      stored.ContentHash,
+		PerceptualHash:   stored.PerceptualHash,
+		DuplicateOfID:    duplicateOfID,
+	}
+
+	updated, err := s.repo.ReplaceFile(artworkID, userID, page, replacement)
+	if err != nil {
+		// If the database update fails, drop the reference to the uploaded file
+		_ = s.fileService.ReleaseFile(&stored.BlobID, stored.Path)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("作品页面不存在")
+		}
+		return nil, err
+	}
+	if updated.PageCount > 1 {
+		if updated.Pages, err = s.repo.GetPages(artworkID); err != nil {
+			return nil, err
+		}
+	}
+
+	return updated, nil
+}
+
+// ErrImageConstraint is returned when an uploaded image violates its activity's image constraints
+var ErrImageConstraint = errors.New("图片不符合活动要求")
+
+// checkActivityImageConstraints checks an uploaded file against the image constraints of an activity
+func (s *ArtworkService) checkActivityImageConstraints(activityID uint, file multipart.File) error {
+	activity, err := s.activityService.GetActivityByID(activityID)
+	if err != nil {
+		return errors.New("活动不存在或已过期")
+	}
+
+	return checkImageConstraints(file, activity.ImageConstraints)
+}
+
+// checkImageConstraints checks the size, format, dimensions and aspect ratio of an uploaded file
+// Only the image header is decoded; dimensions are those after the EXIF orientation is applied
+// on save. The file is rewound afterwards.
+func checkImageConstraints(file multipart.File, c models.ImageConstraints) error {
+	violation := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w：%s", ErrImageConstraint, fmt.Sprintf(format, args...))
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	size := int64(len(data))
+
+	if c.MinBytes > 0 && size < c.MinBytes {
+		return violation("文件大小不能小于 %s（当前 %s）", utils.FormatFileSize(c.MinBytes), utils.FormatFileSize(size))
+	}
+	if c.MaxBytes > 0 && size > c.MaxBytes {
+		return violation("文件大小不能超过 %s（当前 %s）", utils.FormatFileSize(c.MaxBytes), utils.FormatFileSize(size))
+	}
+
+	if !c.NeedsDimensions() {
+		return nil
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return violation("无法识别图片格式或尺寸")
+	}
+
+	if len(c.AllowedFormats) > 0 && !slices.Contains(c.AllowedFormats, format) {
+		return violation("仅接受 %s 格式的图片（当前为 %s）", strings.ToUpper(strings.Join(c.AllowedFormats, "、")), strings.ToUpper(format))
+	}
+
+	width, height := config.Width, config.Height
+	if utils.IsTransposedOrientation(utils.ImageOrientation(data)) {
+		width, height = height, width
+	}
+	if c.MinWidth > 0 && width < c.MinWidth {
+		return violation("图片宽度不能小于 %d 像素（当前 %d 像素）", c.MinWidth, width)
+	}
+	if c.MaxWidth > 0 && width > c.MaxWidth {
+		return violation("图片宽度不能大于 %d 像素（当前 %d 像素）", c.MaxWidth, width)
+	}
+	if c.MinHeight > 0 && height < c.MinHeight {
+		return violation("图片高度不能小于 %d 像素（当前 %d 像素）", c.MinHeight, height)
+	}
+	if c.MaxHeight > 0 && height > c.MaxHeight {
+		return violation("图片高度不能大于 %d 像素（当前 %d 像素）", c.MaxHeight, height)
+	}
+
+	if height > 0 && (c.MinAspectRatio > 0 || c.MaxAspectRatio > 0) {
+		ratio := float64(width) / float64(height)
+		if c.MinAspectRatio > 0 && ratio < c.MinAspectRatio {
+			return violation("图片宽高比（宽/高）不能小于 %.2f（当前 %.2f）", c.MinAspectRatio, ratio)
+		}
+		if c.MaxAspectRatio > 0 && ratio > c.MaxAspectRatio {
+			return violation("图片宽高比（宽/高）不能大于 %.2f（当前 %.2f）", c.MaxAspectRatio, ratio)
+		}
+	}
+
+	return nil
+}
+
+// checkDuplicate looks for another artwork in the activity with exactly the same file
+// Depending on the duplicate policy it returns an error, or the ID of the earliest such artwork to flag the upload with
+func (s *ArtworkService) checkDuplicate(activityID uint, contentHash string, excludeID uint) (*uint, error) {
+	duplicates, err := s.repo.GetByActivityAndContentHash(activityID, contentHash, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(duplicates) == 0 {
+		return nil, nil
+	}
+
+	if s.duplicatePolicy != DuplicatePolicyFlag {
+		return nil, fmt.Errorf("该活动中已存在相同的作品文件（作品ID: %d）", duplicates[0].ID)
+	}
+	return &duplicates[0].ID, nil
+}
+
+// DuplicateMatch is an artwork whose file is identical or visually similar to another artwork's file
+type DuplicateMatch struct {
+	ArtworkID  uint    `json:"artwork_id"`
+	ActivityID uint    `json:"activity_id"`
+	UserID     uint    `json:"user_id"`
+	Title      string  `json:"title"`
+	Similarity float64 `json:"similarity"`
+	Exact      bool    `json:"exact"`
+}
+
+// ReviewQueueItem is an artwork in the review queue together with its possible duplicates
+type ReviewQueueItem struct {
+	models.Artwork
+	PossibleDuplicates []DuplicateMatch `json:"possible_duplicates"`
+}
+
+// GetPossibleDuplicates retrieves artworks, in any activity, whose file is identical or visually similar (admin only)
+func (s *ArtworkService) GetPossibleDuplicates(artworkID uint) ([]DuplicateMatch, error) {
+	artwork, err := s.repo.GetByID(artworkID)
+	if err != nil {
+		return nil, errors.New("作品不存在")
+	}
+
+	return s.findPossibleDuplicates(artwork)
+}
+
+// WithPossibleDuplicates attaches the exact duplicates of each artwork for display in the review queue
+// Comparing perceptual hashes needs a scan of every artwork, so visually similar artworks are only
+// reported by GetPossibleDuplicates for one artwork at a time.
+func (s *ArtworkService) WithPossibleDuplicates(artworks []models.Artwork) ([]ReviewQueueItem, error) {
+	candidates, err := s.repo.FindExactDuplicateCandidates(artworks)
+	if err != nil {
+		return nil, err
+	}
+
+	byHash := make(map[string][]repository.DuplicateCandidate)
+	for _, candidate := range candidates {
+		byHash[candidate.ContentHash] = append(byHash[candidate.ContentHash], candidate)
+	}
+
+	items := make([]ReviewQueueItem, 0, len(artworks))
+	for _, artwork := range artworks {
+		matches := make([]DuplicateMatch, 0)
+		if artwork.ContentHash != "" {
+			for _, candidate := range byHash[artwork.ContentHash] {
+				if candidate.ID == artwork.ID {
+					continue
+				}
+				if len(matches) == maxDuplicateMatches {
+					break
+				}
+				matches = append(matches, DuplicateMatch{
+					ArtworkID:  candidate.ID,
+					ActivityID: candidate.ActivityID,
+					UserID:     candidate.UserID,
+					Title:      candidate.Title,
+					Similarity: 1,
+					Exact:      true,
+				})
+			}
+		}
+		items = append(items, ReviewQueueItem{Artwork: artwork, PossibleDuplicates: matches})
+	}
+	return items, nil
+}
+
+// findPossibleDuplicates converts duplicate candidates of an artwork into matches with a similarity score
+func (s *ArtworkService) findPossibleDuplicates(artwork *models.Artwork) ([]DuplicateMatch, error) {
+	candidates, err := s.repo.FindDuplicateCandidates(artwork, nearDuplicateMaxDistance, maxDuplicateMatches)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]DuplicateMatch, 0, len(candidates))
+	for _, candidate := range candidates {
+		exact := artwork.ContentHash != "" && candidate.ContentHash == artwork.ContentHash
+
+		similarity := 1.0
+		if !exact && candidate.Distance != nil {
+			similarity = math.Round(utils.HashSimilarity(*candidate.Distance)*100) / 100
+		}
+
+		matches = append(matches, DuplicateMatch{
+			ArtworkID:  candidate.ID,
+			ActivityID: candidate.ActivityID,
+			UserID:     candidate.UserID,
+			Title:      candidate.Title,
+			Similarity: similarity,
+			Exact:      exact,
+		})
+	}
+	return matches, nil
+}
+
+// GetRevisions retrieves the previous file revisions of an artwork (admin only)
+func (s *ArtworkService) GetRevisions(artworkID uint) (*models.Artwork, []models.ArtworkRevision, error) {
+	artwork, err := s.repo.GetByID(artworkID)
+	if err != nil {
+		return nil, nil, errors.New("作品不存在")
+	}
+
+	revisions, err := s.repo.GetRevisions(artworkID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return artwork, revisions, nil
+}
+
+// GetRevision retrieves a specific previous file revision of an artwork (admin only)
+func (s *ArtworkService) GetRevision(artworkID uint, revision int) (*models.ArtworkRevision, error) {
+	artworkRevision, err := s.repo.GetRevision(artworkID, revision)
+	if err != nil {
+		return nil, errors.New("作品版本不存在")
+	}
+	return artworkRevision, nil
+}
+
 // GetArtwork retrieves an artwork with permission validation
 // Requirements: 5.5, 6.1, 6.2, 6.3
 func (s *ArtworkService) GetArtwork(artworkID, requesterID uint, requesterRole string) (interface{}, error) {
@@ -77,6 +769,17 @@ func (s *ArtworkService) GetArtwork(artworkID, requesterID uint, requesterRole s
 		return nil, errors.New("artwork not found")
 	}
 
+	if artwork.PageCount > 1 {
+		if artwork.Pages, err = s.repo.GetPages(artworkID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Drafts are only visible to the author, admins included
+	if artwork.ReviewStatus == models.StatusDraft && artwork.UserID != requesterID {
+		return nil, errors.New("permission denied: you can only view your own artworks")
+	}
+
 	// Admin can access all artworks
 	if requesterRole == "admin" {
 		return artwork, nil
@@ -87,7 +790,8 @@ func (s *ArtworkService) GetArtwork(artworkID, requesterID uint, requesterRole s
 		return nil, errors.New("permission denied: artwork is pending review")
 	}
 
-	// Approved artworks are only visible to the author
+	// Reviewed artworks (approved or rejected) are only visible to the author,
+	// so rejected artworks carry their reject reason back to the author
 	if artwork.UserID != requesterID {
 		return nil, errors.New("permission denied: you can only view your own artworks")
 	}
@@ -95,48 +799,166 @@ func (s *ArtworkService) GetArtwork(artworkID, requesterID uint, requesterRole s
 	return artwork, nil
 }
 
+// GetArtworkFilePath retrieves the stored file path of a page of an artwork without permission checks
+// It is used to serve signed image URLs, whose signature already grants access; artworks in
+// the trash are included so the trash can show their images
+func (s *ArtworkService) GetArtworkFilePath(artworkID uint, page int) (string, error) {
+	artwork, err := s.repo.GetByIDIncludingDeleted(artworkID)
+	if err != nil {
+		return "", errors.New("作品不存在")
+	}
+	return s.GetArtworkPageFilePath(artwork, page)
+}
+
+// ReviewDecision describes the outcome of reviewing one or more artworks
+type ReviewDecision struct {
+	ReviewerID  uint
+	Status      models.ReviewStatus
+	Reason      string
+	ReasonCodes []models.RejectReasonCode
+	Comment     string
+}
+
+// toUpdate converts the decision into a repository review update, recording the
+// reject reason as the history comment when no separate comment is given
+func (d ReviewDecision) toUpdate() repository.ReviewUpdate {
+	comment := strings.TrimSpace(d.Comment)
+	if comment == "" {
+		comment = d.Reason
+	}
+
+	return repository.ReviewUpdate{
+		ReviewerID:        d.ReviewerID,
+		Status:            d.Status,
+		RejectReason:      d.Reason,
+		RejectReasonCodes: d.ReasonCodes,
+		Comment:           comment,
+	}
+}
+
+// maxRejectReasonLength limits the length of a reviewer-supplied reject reason
+const maxRejectReasonLength = 500
+
+// validateReviewDecision checks the decision and clears reject details for non-rejected outcomes
+func validateReviewDecision(decision *ReviewDecision) error {
+	if !decision.Status.IsValid() {
+		return errors.New("无效的审核状态")
+	}
+
+	if decision.Status != models.StatusRejected {
+		decision.Reason = ""
+		decision.ReasonCodes = nil
+		return nil
+	}
+
+	decision.Reason = strings.TrimSpace(decision.Reason)
+	if decision.Reason == "" {
+		return errors.New("驳回作品时必须填写驳回原因")
+	}
+	if utf8.RuneCountInString(decision.Reason) > maxRejectReasonLength {
+		return fmt.Errorf("驳回原因不能超过%d个字符", maxRejectReasonLength)
+	}
+	for _, code := range decision.ReasonCodes {
+		if _, ok := models.RejectReasonLabels[code]; !ok {
+			return fmt.Errorf("无效的驳回原因代码: %s", code)
+		}
+	}
+
+	return nil
+}
+
 // ReviewArtwork updates the review status of a single artwork
 // Requirements: 5.2, 5.3, 5.4
-func (s *ArtworkService) ReviewArtwork(artworkID uint, approved bool) error {
+func (s *ArtworkService) ReviewArtwork(artworkID uint, decision ReviewDecision) error {
+	if err := validateReviewDecision(&decision); err != nil {
+		return err
+	}
+
 	// Check if artwork exists
 	exists, err := s.repo.Exists(artworkID)
 	if err != nil {
 		return err
 	}
 	if !exists {
-		return errors.New("artwork not found")
+		return errors.New("作品不存在")
 	}
 
-	// Determine new status
-	status := models.StatusPending
-	if approved {
-		status = models.StatusApproved
+	// Update review status and record it in the review history; drafts are not found
+	if err := s.repo.UpdateReviewStatus(artworkID, decision.toUpdate()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("作品不存在")
+		}
+		return err
 	}
-
-	// Update review status
-	return s.repo.UpdateReviewStatus(artworkID, status)
+	return nil
 }
 
-// BatchReviewArtworks updates the review status of multiple artworks
+// BatchReviewArtworks updates the review status of multiple artworks and returns how many were updated
+// Artworks that do not exist, are in the trash or are drafts are skipped
 // Requirements: 5.2, 5.3, 5.4
-func (s *ArtworkService) BatchReviewArtworks(artworkIDs []uint, approved bool) error {
+func (s *ArtworkService) BatchReviewArtworks(artworkIDs []uint, decision ReviewDecision) (int, error) {
 	if len(artworkIDs) == 0 {
-		return errors.New("no artwork IDs provided")
+		return 0, errors.New("no artwork IDs provided")
+	}
+
+	if err := validateReviewDecision(&decision); err != nil {
+		return 0, err
+	}
+
+	// Batch update review status and record each decision in the review history
+	count, err := s.repo.BatchUpdateReviewStatus(artworkIDs, decision.toUpdate())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("作品不存在")
+		}
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetReviewHistory retrieves the review timeline of an artwork (admin only)
+func (s *ArtworkService) GetReviewHistory(artworkID uint) ([]models.ArtworkReview, error) {
+	exists, err := s.repo.Exists(artworkID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("作品不存在")
+	}
+
+	return s.reviewRepo.GetByArtworkID(artworkID)
+}
+
+// GetOriginalMetadata retrieves the image metadata stripped from an artwork's current file (admin only)
+// The result is empty unless keeping original metadata is enabled in the upload configuration
+func (s *ArtworkService) GetOriginalMetadata(artworkID uint) (map[string]string, error) {
+	artwork, err := s.repo.GetByID(artworkID)
+	if err != nil {
+		return nil, errors.New("作品不存在")
 	}
 
-	// Determine new status
-	status := models.StatusPending
-	if approved {
-		status = models.StatusApproved
+	if artwork.OriginalMetadata == nil {
+		return map[string]string{}, nil
 	}
+	return artwork.OriginalMetadata, nil
+}
 
-	// Batch update review status
-	return s.repo.BatchUpdateReviewStatus(artworkIDs, status)
+// GetReviewerActivity retrieves the review decisions made by a reviewer (admin only)
+func (s *ArtworkService) GetReviewerActivity(reviewerID uint, page, pageSize int) ([]models.ArtworkReview, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	return s.reviewRepo.GetByReviewerID(reviewerID, page, pageSize)
 }
 
-// GetReviewQueue retrieves pending artworks sorted by upload time
+// GetReviewQueue retrieves pending artworks sorted by upload time, skipping the excluded artworks
 // Requirements: 5.3, 5.4, 8.5
-func (s *ArtworkService) GetReviewQueue(page, pageSize int) ([]models.Artwork, int64, error) {
+func (s *ArtworkService) GetReviewQueue(page, pageSize int, excludeIDs []uint) ([]models.Artwork, int64, error) {
 	if page <= 0 {
 		page = 1
 	}
@@ -144,10 +966,10 @@ func (s *ArtworkService) GetReviewQueue(page, pageSize int) ([]models.Artwork, i
 		pageSize = 10
 	}
 
-	return s.repo.GetReviewQueue(page, pageSize)
+	return s.repo.GetReviewQueue(page, pageSize, excludeIDs)
 }
 
-// DeleteArtwork deletes an artwork and its associated file
+// DeleteArtwork moves an artwork to the trash, from which it can be restored until it is purged
 // Requirements: 4.5
 func (s *ArtworkService) DeleteArtwork(artworkID, requesterID uint, requesterRole string) error {
 	// Retrieve artwork
@@ -161,17 +983,197 @@ func (s *ArtworkService) DeleteArtwork(artworkID, requesterID uint, requesterRol
 		return errors.New("permission denied: you can only delete your own artworks")
 	}
 
-	// Delete the physical file
-	if err := s.fileService.DeleteFile(artwork.FilePath); err != nil {
-		// Log error but continue with database deletion
-		// File might already be deleted or path might be invalid
+	// The files are kept until the artwork is purged from the trash
+	if err := s.repo.SoftDelete(artworkID); err != nil {
+		return err
+	}
+
+	// Votes for the artwork stop counting toward the voters' limits; if the counters cannot be
+	// reloaded now, they are reloaded with the next vote sync of the activity
+	_ = s.voteService.ReloadArtworkVotes(artwork)
+	return nil
+}
+
+// GetTrash retrieves the artworks in the trash, most recently deleted first
+// Administrators see every author's deleted artworks when allUsers is set; otherwise only the requester's
+func (s *ArtworkService) GetTrash(requesterID uint, requesterRole string, allUsers bool, page, pageSize int) ([]models.Artwork, int64, error) {
+	if allUsers && requesterRole != "admin" {
+		return nil, 0, errors.New("权限不足，仅管理员可查看所有用户的回收站")
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	userID := requesterID
+	if allUsers {
+		userID = 0
+	}
+	return s.repo.GetDeletedWithPagination(userID, page, pageSize)
+}
+
+// TrashRetention returns how long deleted artworks stay in the trash
+func (s *ArtworkService) TrashRetention() time.Duration {
+	return s.trashRetention
+}
+
+// RestoreArtwork takes an artwork out of the trash (owner or admin)
+// A restored artwork counts toward the activity's upload limit again, so it cannot be
+// restored while the author has reached the limit; its review status is kept
+func (s *ArtworkService) RestoreArtwork(artworkID, requesterID uint, requesterRole string) (*models.Artwork, error) {
+	artwork, err := s.repo.GetDeletedByID(artworkID)
+	if err != nil {
+		return nil, errors.New("回收站中不存在该作品")
+	}
+	if requesterRole != "admin" && artwork.UserID != requesterID {
+		return nil, errors.New("权限不足，只能恢复自己的作品")
+	}
+
+	activity, err := s.activityService.GetActivityByID(artwork.ActivityID)
+	if err != nil {
+		return nil, errors.New("作品所属活动不存在，无法恢复")
+	}
+
+	restored, err := s.repo.Restore(artwork, activity.MaxUploadsPerUser)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("回收站中不存在该作品")
+		}
+		return nil, err
+	}
+	if !restored {
+		return nil, errors.New("超过了该活动的上传数量限制，无法恢复")
+	}
+
+	// Votes for the artwork count toward the voters' limits again
+	_ = s.voteService.ReloadArtworkVotes(artwork)
+
+	return artwork, nil
+}
+
+// PurgeDeletedArtworks permanently deletes the artworks that have been in the trash for
+// longer than the retention period, with their files, and returns how many were deleted
+func (s *ArtworkService) PurgeDeletedArtworks() (int, error) {
+	const batchSize = 100
+
+	purged := 0
+	for {
+		artworks, err := s.repo.GetDeletedBefore(time.Now().Add(-s.trashRetention), batchSize)
+		if err != nil {
+			return purged, err
+		}
+		for i := range artworks {
+			if err := s.deleteArtwork(&artworks[i]); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if len(artworks) < batchSize {
+			return purged, nil
+		}
+	}
+}
+
+// deleteArtwork permanently deletes an artwork record and releases its files
+func (s *ArtworkService) deleteArtwork(artwork *models.Artwork) error {
+	// Page and revision rows are removed with the artwork, so collect their files first
+	pages, err := s.repo.GetPages(artwork.ID)
+	if err != nil {
+		return err
+	}
+	revisions, err := s.repo.GetRevisions(artwork.ID)
+	if err != nil {
+		return err
 	}
 
 	// Delete artwork record from database
-	return s.repo.Delete(artworkID)
+	if err := s.repo.Delete(artwork.ID); err != nil {
+		return err
+	}
+
+	// Drop the references to the files; a file shared with other artworks is kept.
+	// Failures leave an unreferenced file behind but do not undo the deletion.
+	_ = s.fileService.ReleaseFile(artwork.BlobID, artwork.FilePath)
+	for _, page := range pages {
+		_ = s.fileService.ReleaseFile(page.BlobID, page.FilePath)
+	}
+	for _, revision := range revisions {
+		_ = s.fileService.ReleaseFile(revision.BlobID, revision.FilePath)
+	}
+
+	return nil
+}
+
+// SubmitArtwork moves one of the author's drafts into the review queue
+// The activity must still accept uploads, and the draft now counts toward its upload limit
+func (s *ArtworkService) SubmitArtwork(artworkID, userID uint) (*models.Artwork, error) {
+	artwork, err := s.repo.GetByID(artworkID)
+	if err != nil {
+		return nil, errors.New("作品不存在")
+	}
+	if artwork.UserID != userID {
+		return nil, errors.New("权限不足，只能提交自己的作品")
+	}
+	if artwork.ReviewStatus != models.StatusDraft {
+		return nil, errors.New("作品不是草稿，无需提交")
+	}
+
+	if err := s.checkCanUpload(userID, artwork.ActivityID, false); err != nil {
+		return nil, err
+	}
+
+	// Other artworks may have been submitted with the same file since the draft was saved
+	duplicateOfID, err := s.checkDuplicate(artwork.ActivityID, artwork.ContentHash, artworkID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the upload limit again while submitting, as concurrent uploads may have passed the check above
+	activity, err := s.activityService.GetActivityByID(artwork.ActivityID)
+	if err != nil {
+		return nil, err
+	}
+	submitted, err := s.repo.SubmitDraft(artwork, activity.MaxUploadsPerUser, duplicateOfID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("作品不是草稿，无需提交")
+		}
+		return nil, err
+	}
+	if !submitted {
+		return nil, errors.New("超过了该活动的上传数量限制")
+	}
+
+	return artwork, nil
+}
+
+// DiscardExpiredDrafts deletes the drafts whose activity has passed its deadline or been
+// deleted, as they can no longer be submitted, and returns how many were deleted
+func (s *ArtworkService) DiscardExpiredDrafts() (int, error) {
+	const batchSize = 100
+
+	discarded := 0
+	for {
+		drafts, err := s.repo.GetExpiredDrafts(time.Now(), batchSize)
+		if err != nil {
+			return discarded, err
+		}
+		for i := range drafts {
+			if err := s.deleteArtwork(&drafts[i]); err != nil {
+				return discarded, err
+			}
+			discarded++
+		}
+		if len(drafts) < batchSize {
+			return discarded, nil
+		}
+	}
 }
 
 // CheckUploadLimit checks if a user can upload more artworks to an activity
+// Drafts do not count toward the limit
 // Requirements: 4.2
 func (s *ArtworkService) CheckUploadLimit(userID, activityID uint) (bool, error) {
 	// Get activity to check max uploads limit
@@ -191,7 +1193,8 @@ func (s *ArtworkService) CheckUploadLimit(userID, activityID uint) (bool, error)
 }
 
 // GetArtworksByActivity retrieves all artworks for a specific activity (admin only)
-func (s *ArtworkService) GetArtworksByActivity(activityID uint, page, pageSize int) ([]models.Artwork, int64, error) {
+// When tag is not empty only artworks carrying that tag are returned
+func (s *ArtworkService) GetArtworksByActivity(activityID uint, page, pageSize int, tag string) ([]models.Artwork, int64, error) {
 	if page <= 0 {
 		page = 1
 	}
@@ -205,5 +1208,5 @@ func (s *ArtworkService) GetArtworksByActivity(activityID uint, page, pageSize i
 		return nil, 0, errors.New("活动不存在")
 	}
 
-	return s.repo.GetByActivityIDWithPagination(activityID, page, pageSize)
+	return s.repo.GetByActivityIDWithPagination(activityID, page, pageSize, strings.TrimSpace(tag))
 }
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/repository"
+	"art-collection-system/internal/storage"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to the MySQL database named by TEST_MYSQL_DSN, which must have been
+// created with scripts/init_db.sql; the test is skipped when it is not set
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("TEST_MYSQL_DSN not set")
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to MySQL: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get SQL DB: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// testFile is an in-memory upload
+type testFile struct {
+	*bytes.Reader
+}
+
+func (testFile) Close() error { return nil }
+
+// newTestPNG encodes a small PNG filled with a color derived from seed, so each seed gives different content
+func newTestPNG(t *testing.T, seed int) testFile {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	fill := color.RGBA{R: uint8(seed), G: uint8(seed >> 8), B: 0x80, A: 0xff}
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode PNG: %v", err)
+	}
+	return testFile{bytes.NewReader(buf.Bytes())}
+}
+
+func TestUploadArtworkConcurrentLimit(t *testing.T) {
+	db := openTestDB(t)
+
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	artworkRepo := repository.NewArtworkRepository(db)
+	activityService := NewActivityService(repository.NewActivityRepository(db))
+	fileService := NewFileService(store, repository.NewBlobRepository(db), false)
+	artworkService := NewArtworkService(artworkRepo, repository.NewArtworkReviewRepository(db), activityService, fileService, nil, DuplicatePolicyReject, time.Hour)
+
+	const limit = 3
+	const uploads = 10
+
+	user := &models.User{
+		Email:    fmt.Sprintf("upload-limit-%d@example.com", time.Now().UnixNano()),
+		Password: "x",
+		Nickname: "upload-limit",
+		Role:     "user",
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	activity := &models.Activity{Name: "upload-limit", MaxUploadsPerUser: limit}
+	if err := db.Create(activity).Error; err != nil {
+		t.Fatalf("failed to create activity: %v", err)
+	}
+	t.Cleanup(func() {
+		var blobIDs []uint
+		db.Model(&models.Artwork{}).Where("activity_id = ?", activity.ID).Pluck("blob_id", &blobIDs)
+		db.Where("activity_id = ?", activity.ID).Delete(&models.Artwork{})
+		db.Where("activity_id = ?", activity.ID).Delete(&models.UploadQuota{})
+		if len(blobIDs) > 0 {
+			db.Delete(&models.Blob{}, blobIDs)
+		}
+		db.Delete(activity)
+		db.Delete(user)
+	})
+
+	// Each upload has different content, so only the limit can turn one away
+	files := make([]testFile, uploads)
+	for i := range files {
+		files[i] = newTestPNG(t, int(activity.ID)*uploads+i)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, uploads)
+	start := make(chan struct{})
+	for i := 0; i < uploads; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			upload := []UploadedFile{{File: files[i], Filename: fmt.Sprintf("%d.png", i)}}
+			_, errs[i] = artworkService.UploadArtwork(user.ID, activity.ID, upload, ArtworkMetadata{}, false)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	accepted := 0
+	for i := 0; i < uploads; i++ {
+		switch {
+		case errs[i] == nil:
+			accepted++
+		case errs[i].Error() != "超过了该活动的上传数量限制":
+			t.Fatalf("upload %d failed: %v", i, errs[i])
+		}
+	}
+	if accepted != limit {
+		t.Errorf("accepted %d uploads, want %d", accepted, limit)
+	}
+
+	var stored int64
+	if err := db.Model(&models.Artwork{}).Where("user_id = ? AND activity_id = ?", user.ID, activity.ID).Count(&stored).Error; err != nil {
+		t.Fatalf("failed to count artworks: %v", err)
+	}
+	if stored != limit {
+		t.Errorf("stored %d artworks, want %d", stored, limit)
+	}
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/repository"
+	"art-collection-system/internal/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// maxCommentLength is the maximum length of a comment in characters
+const maxCommentLength = 2000
+
+// CommentView is a comment as shown on an artwork, with the replies below it
+// It deliberately omits private author details such as the email address
+type CommentView struct {
+	ID         uint           `json:"id"`
+	ArtworkID  uint           `json:"artwork_id"`
+	ParentID   *uint          `json:"parent_id"`
+	AuthorID   uint           `json:"author_id"`
+	AuthorName string         `json:"author_name"`
+	Content    string         `json:"content"`
+	IsHidden   bool           `json:"is_hidden"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Replies    []*CommentView `json:"replies"`
+}
+
+// newCommentView builds the view of a comment
+// The content of a hidden comment is only shown to admins; others see that it was hidden
+func newCommentView(comment *models.Comment, showHidden bool) *CommentView {
+	view := &CommentView{
+		ID:        comment.ID,
+		ArtworkID: comment.ArtworkID,
+		ParentID:  comment.ParentID,
+		AuthorID:  comment.UserID,
+		Content:   comment.Content,
+		IsHidden:  comment.IsHidden,
+		CreatedAt: comment.CreatedAt,
+		Replies:   []*CommentView{},
+	}
+	if comment.User != nil {
+		view.AuthorName = comment.User.Nickname
+	}
+	if comment.IsHidden && !showHidden {
+		view.Content = ""
+	}
+	return view
+}
+
+// CommentService handles comments on artworks and their moderation
+type CommentService struct {
+	commentRepo     *repository.CommentRepository
+	artworkRepo     *repository.ArtworkRepository
+	userRepo        *repository.UserRepository
+	activityService *ActivityService
+	emailService    *utils.EmailService
+}
+
+// NewCommentService creates a new comment service instance
+func NewCommentService(commentRepo *repository.CommentRepository, artworkRepo *repository.ArtworkRepository, userRepo *repository.UserRepository, activityService *ActivityService, emailService *utils.EmailService) *CommentService {
+	return &CommentService{
+		commentRepo:     commentRepo,
+		artworkRepo:     artworkRepo,
+		userRepo:        userRepo,
+		activityService: activityService,
+		emailService:    emailService,
+	}
+}
+
+// commentableArtwork retrieves an artwork whose comments the requester may read and write
+// Comments are visible to the author and admins (drafts to the author only), and to everyone
+// while the artwork is shown in its activity's public gallery
+func (s *CommentService) commentableArtwork(artworkID, requesterID uint, requesterRole string) (*models.Artwork, error) {
+	artwork, err := s.artworkRepo.GetByIDWithUser(artworkID)
+	if err != nil {
+		return nil, errors.New("作品不存在")
+	}
+
+	if artwork.UserID == requesterID {
+		return artwork, nil
+	}
+	if artwork.ReviewStatus == models.StatusDraft {
+		return nil, errors.New("作品不存在")
+	}
+	if requesterRole == "admin" || s.inOpenGallery(artwork) {
+		return artwork, nil
+	}
+
+	return nil, errors.New("权限不足，无法查看该作品的评论")
+}
+
+// inOpenGallery reports whether an artwork is shown in its activity's public gallery
+func (s *CommentService) inOpenGallery(artwork *models.Artwork) bool {
+	if artwork.ReviewStatus != models.StatusApproved {
+		return false
+	}
+	activity, err := s.activityService.GetActivityByID(artwork.ActivityID)
+	return err == nil && activity.IsGalleryOpen(time.Now())
+}
+
+// GetComments retrieves the comment threads on an artwork with pagination, newest thread first
+// Replies are nested below the comments they reply to, oldest first
+func (s *CommentService) GetComments(artworkID, requesterID uint, requesterRole string, page, pageSize int) ([]*CommentView, int64, error) {
+	if _, err := s.commentableArtwork(artworkID, requesterID, requesterRole); err != nil {
+		return nil, 0, err
+	}
+	return s.getThreads(artworkID, requesterRole == "admin", page, pageSize)
+}
+
+// GetGalleryComments retrieves the comment threads on an artwork in an activity's public gallery
+func (s *CommentService) GetGalleryComments(activityID, artworkID uint, page, pageSize int) ([]*CommentView, int64, error) {
+	artwork, err := s.artworkRepo.GetByID(artworkID)
+	if err != nil || artwork.ActivityID != activityID || !s.inOpenGallery(artwork) {
+		return nil, 0, errors.New("作品不存在")
+	}
+	return s.getThreads(artworkID, false, page, pageSize)
+}
+
+// getThreads retrieves a page of comment threads on an artwork and builds their reply trees
+func (s *CommentService) getThreads(artworkID uint, showHidden bool, page, pageSize int) ([]*CommentView, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	threads, total, err := s.commentRepo.GetThreadsByArtwork(artworkID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	views := make([]*CommentView, 0, len(threads))
+	byID := make(map[uint]*CommentView, len(threads))
+	rootIDs := make([]uint, 0, len(threads))
+	for i := range threads {
+		view := newCommentView(&threads[i], showHidden)
+		views = append(views, view)
+		byID[view.ID] = view
+		rootIDs = append(rootIDs, view.ID)
+	}
+
+	replies, err := s.commentRepo.GetReplies(rootIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Replies are ordered oldest first, so a reply's parent is always seen before it
+	for i := range replies {
+		view := newCommentView(&replies[i], showHidden)
+		byID[view.ID] = view
+
+		parent := byID[*replies[i].RootID]
+		if replies[i].ParentID != nil && byID[*replies[i].ParentID] != nil {
+			parent = byID[*replies[i].ParentID]
+		}
+		if parent != nil {
+			parent.Replies = append(parent.Replies, view)
+		}
+	}
+
+	return views, total, nil
+}
+
+// AddComment adds a comment to an artwork, or a reply to another comment when parentID is set,
+// and notifies the artwork's author by email
+func (s *CommentService) AddComment(artworkID, userID uint, userRole string, parentID *uint, content string) (*CommentView, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("评论内容不能为空")
+	}
+	if utf8.RuneCountInString(content) > maxCommentLength {
+		return nil, fmt.Errorf("评论内容不能超过 %d 个字符", maxCommentLength)
+	}
+
+	artwork, err := s.commentableArtwork(artworkID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		ArtworkID: artworkID,
+		UserID:    userID,
+		Content:   content,
+	}
+	if parentID != nil {
+		parent, err := s.commentRepo.GetByID(*parentID)
+		if err != nil || parent.ArtworkID != artworkID {
+			return nil, errors.New("回复的评论不存在")
+		}
+		rootID := parent.ID
+		if parent.RootID != nil {
+			rootID = *parent.RootID
+		}
+		comment.ParentID = &parent.ID
+		comment.RootID = &rootID
+	}
+
+	if err := s.commentRepo.Create(comment); err != nil {
+		return nil, err
+	}
+
+	commenter, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	comment.User = commenter
+
+	if artwork.UserID != userID {
+		go s.notifyAuthor(artwork, commenter.Nickname, content)
+	}
+
+	return newCommentView(comment, true), nil
+}
+
+// notifyAuthor emails an artwork's author about a new comment
+// Failures are only logged; the comment has already been saved
+func (s *CommentService) notifyAuthor(artwork *models.Artwork, commenterName, content string) {
+	title := artwork.Title
+	if title == "" {
+		title = artwork.FileName
+	}
+
+	err := s.emailService.SendCommentNotification(artwork.User.Email, artwork.User.Nickname, title, commenterName, content)
+	if err != nil {
+		fmt.Printf("Warning: Failed to send comment notification to %s: %v\n", artwork.User.Email, err)
+	}
+}
+
+// DeleteComment permanently deletes a comment
+// Users can delete their own comments as long as nobody has replied to them; admins can
+// delete any comment, together with the replies below it
+func (s *CommentService) DeleteComment(commentID, requesterID uint, requesterRole string) error {
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("评论不存在")
+		}
+		return err
+	}
+
+	if requesterRole == "admin" {
+		_, err = s.commentRepo.DeleteWithReplies(comment)
+		return err
+	}
+
+	if comment.UserID != requesterID {
+		return errors.New("权限不足，只能删除自己的评论")
+	}
+
+	// Other users' replies must not disappear along with the comment
+	deleted, err := s.commentRepo.DeleteIfNoReplies(comment.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("评论不存在")
+		}
+		return err
+	}
+	if !deleted {
+		return errors.New("该评论已有回复，无法删除")
+	}
+	return nil
+}
+
+// SetCommentHidden hides a comment from everyone but admins, or shows it again (admin only)
+// The replies to a hidden comment stay visible
+func (s *CommentService) SetCommentHidden(commentID, adminID uint, hidden bool) error {
+	if _, err := s.commentRepo.GetByID(commentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("评论不存在")
+		}
+		return err
+	}
+	return s.commentRepo.SetHidden(commentID, hidden, adminID)
+}
+
+// ListComments retrieves comments on all artworks for moderation with pagination, newest first (admin only)
+func (s *CommentService) ListComments(hidden *bool, artworkID uint, page, pageSize int) ([]models.Comment, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	return s.commentRepo.List(hidden, artworkID, page, pageSize)
+}
@@ -0,0 +1,445 @@
+package service
+
+import (
+	"archive/zip"
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/repository"
+	"art-collection-system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxExportNameLength limits the length in characters of folder and file names in exports
+const maxExportNameLength = 80
+
+// Header languages of tabular exports
+const (
+	ExportLanguageZH = "zh"
+	ExportLanguageEN = "en"
+)
+
+// ExportService handles bulk exports of activity submissions and users for administrators
+type ExportService struct {
+	artworkRepo     *repository.ArtworkRepository
+	userRepo        *repository.UserRepository
+	activityService *ActivityService
+	fileService     *FileService
+}
+
+// NewExportService creates a new export service instance
+func NewExportService(artworkRepo *repository.ArtworkRepository, userRepo *repository.UserRepository, activityService *ActivityService, fileService *FileService) *ExportService {
+	return &ExportService{
+		artworkRepo:     artworkRepo,
+		userRepo:        userRepo,
+		activityService: activityService,
+		fileService:     fileService,
+	}
+}
+
+// ActivityExport is the set of artworks exported from an activity
+type ActivityExport struct {
+	Activity *models.Activity
+	Artworks []models.Artwork
+}
+
+// GetActivityExport loads the artworks of an activity to export, optionally filtered by review status
+// It is called before any response is written so that errors can still be reported
+func (s *ExportService) GetActivityExport(activityID uint, statuses []models.ReviewStatus) (*ActivityExport, error) {
+	for _, status := range statuses {
+		if !status.IsValid() {
+			return nil, errors.New("无效的审核状态")
+		}
+	}
+
+	activity, err := s.activityService.GetActivityByID(activityID)
+	if err != nil {
+		return nil, errors.New("活动不存在")
+	}
+
+	artworks, err := s.artworkRepo.GetForExport(activityID, statuses)
+	if err != nil {
+		return nil, errors.New("获取活动作品失败")
+	}
+
+	return &ActivityExport{Activity: activity, Artworks: artworks}, nil
+}
+
+// WriteZip streams an activity export as a ZIP archive to w
+// Each author gets a folder named "{user_id}_{nickname}" holding files named
+// "{artwork_id}_{title}{ext}"; a multi-page artwork gets a folder of that name
+// instead, holding its pages as "{page}{ext}". manifest.csv lists every artwork.
+// Files are stored uncompressed since images are already compressed. Artworks
+// whose file is missing are listed in the manifest without a file name.
+func (s *ExportService) WriteZip(ctx context.Context, w io.Writer, export *ActivityExport) error {
+	archive := zip.NewWriter(w)
+
+	files := make([]string, len(export.Artworks))
+	for i := range export.Artworks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		artwork := &export.Artworks[i]
+		modified := artwork.CreatedAt
+		if artwork.FileUpdatedAt != nil {
+			modified = *artwork.FileUpdatedAt
+		}
+
+		name := exportFilePath(artwork)
+		if len(artwork.Pages) == 0 {
+			written, err := s.writeZipEntry(archive, name, artwork.ID, artwork.FilePath, modified)
+			if err != nil {
+				return fmt.Errorf("failed to export artwork %d: %w", artwork.ID, err)
+			}
+			if written {
+				files[i] = name
+			}
+			continue
+		}
+
+		// Multi-page artwork: one folder, pages numbered with a fixed width so they sort in order
+		folder := strings.TrimSuffix(name, filepath.Ext(name)) + "/"
+		width := len(strconv.Itoa(len(artwork.Pages) + 1))
+		paths := []string{artwork.FilePath}
+		for _, page := range artwork.Pages {
+			paths = append(paths, page.FilePath)
+		}
+		for page, filePath := range paths {
+			pageName := fmt.Sprintf("%s%0*d%s", folder, width, page+1, strings.ToLower(filepath.Ext(filePath)))
+			written, err := s.writeZipEntry(archive, pageName, artwork.ID, filePath, modified)
+			if err != nil {
+				return fmt.Errorf("failed to export artwork %d: %w", artwork.ID, err)
+			}
+			if written {
+				files[i] = folder
+			}
+		}
+	}
+
+	manifest, err := archive.Create("manifest.csv")
+	if err != nil {
+		return err
+	}
+	if err := writeExportManifest(manifest, export.Artworks, files); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// writeZipEntry copies a file of an artwork into the archive
+// It reports false without error when the file is missing from storage
+func (s *ExportService) writeZipEntry(archive *zip.Writer, name string, artworkID uint, filePath string, modified time.Time) (bool, error) {
+	file, err := s.fileService.OpenReader(filePath)
+	if err != nil {
+		// Open before creating the entry, so a missing file does not leave an empty one
+		log.Printf("export: artwork %d file %s: %v", artworkID, filePath, err)
+		return false, nil
+	}
+	defer file.Close()
+
+	entry, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: modified,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := io.Copy(entry, file); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeExportManifest writes the CSV manifest of an export
+// files holds the path of each artwork's file (or pages folder) in the archive, or "" when it is missing
+// User-supplied text is escaped by the table writer so spreadsheet programs do not run it as a formula
+func writeExportManifest(w io.Writer, artworks []models.Artwork, files []string) error {
+	writer, err := utils.NewTableWriter(utils.TableFormatCSV, w)
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteRow([]interface{}{"artwork_id", "author", "email", "title", "status", "pages", "file"}); err != nil {
+		return err
+	}
+	for i, artwork := range artworks {
+		row := []interface{}{
+			artwork.ID,
+			artwork.User.Nickname,
+			artwork.User.Email,
+			artwork.Title,
+			string(artwork.ReviewStatus),
+			artwork.PageCount,
+			files[i],
+		}
+		if err := writer.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// exportFilePath builds the path of an artwork's file inside an export archive
+func exportFilePath(artwork *models.Artwork) string {
+	folder := strconv.FormatUint(uint64(artwork.UserID), 10)
+	if nickname := sanitizeExportName(artwork.User.Nickname); nickname != "" {
+		folder += "_" + nickname
+	}
+
+	ext := strings.ToLower(filepath.Ext(artwork.FilePath))
+	title := artwork.Title
+	if strings.TrimSpace(title) == "" {
+		title = strings.TrimSuffix(artwork.FileName, filepath.Ext(artwork.FileName))
+	}
+	name := strconv.FormatUint(uint64(artwork.ID), 10)
+	if title := sanitizeExportName(title); title != "" {
+		name += "_" + title
+	}
+
+	return folder + "/" + name + ext
+}
+
+// sanitizeExportName turns user-supplied text into a name that is safe as a file
+// or folder name on common operating systems, keeping non-ASCII letters
+func sanitizeExportName(name string) string {
+	var b strings.Builder
+	count := 0
+	for _, r := range name {
+		if count >= maxExportNameLength {
+			break
+		}
+		switch {
+		case unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r):
+			r = '_'
+		case unicode.IsSpace(r):
+			r = ' '
+		}
+		b.WriteRune(r)
+		count++
+	}
+	// Windows does not allow names ending in a dot or space
+	return strings.Trim(b.String(), " ._")
+}
+
+// IsValidExportLanguage reports whether language is a supported header language
+func IsValidExportLanguage(language string) bool {
+	return language == ExportLanguageZH || language == ExportLanguageEN
+}
+
+// ExportColumn is a column of a tabular export with its header in each language
+type ExportColumn struct {
+	EN string
+	ZH string
+}
+
+// header returns the column header in the given language
+func (c ExportColumn) header(language string) string {
+	if language == ExportLanguageEN {
+		return c.EN
+	}
+	return c.ZH
+}
+
+// TableExport is a tabular export whose rows are read from the database while it is written
+type TableExport struct {
+	// Name is the download file name without extension
+	Name    string
+	Columns []ExportColumn
+	rows    func(language string, emit func(cells []interface{}) error) error
+}
+
+// WriteTable streams a tabular export to w in the given format with headers in the given language
+func (s *ExportService) WriteTable(w io.Writer, export *TableExport, format, language string) error {
+	table, err := utils.NewTableWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	headers := make([]interface{}, len(export.Columns))
+	for i, column := range export.Columns {
+		headers[i] = column.header(language)
+	}
+	if err := table.WriteRow(headers); err != nil {
+		return err
+	}
+
+	if err := export.rows(language, table.WriteRow); err != nil {
+		return err
+	}
+	return table.Close()
+}
+
+// artworkTableColumns are the columns of the activity artworks export
+var artworkTableColumns = []ExportColumn{
+	{EN: "ID", ZH: "作品ID"},
+	{EN: "Title", ZH: "标题"},
+	{EN: "Author", ZH: "作者"},
+	{EN: "Email", ZH: "邮箱"},
+	{EN: "File name", ZH: "文件名"},
+	{EN: "Pages", ZH: "页数"},
+	{EN: "Medium", ZH: "媒介"},
+	{EN: "Width (cm)", ZH: "宽（厘米）"},
+	{EN: "Height (cm)", ZH: "高（厘米）"},
+	{EN: "Depth (cm)", ZH: "厚（厘米）"},
+	{EN: "Year", ZH: "创作年份"},
+	{EN: "Tags", ZH: "标签"},
+	{EN: "Status", ZH: "审核状态"},
+	{EN: "Reject reason", ZH: "驳回原因"},
+	{EN: "Submitted at", ZH: "提交时间"},
+}
+
+// ActivityArtworksTable prepares the export of an activity's artworks, applying the same
+// tag filter as the admin activity artworks listing
+func (s *ExportService) ActivityArtworksTable(activityID uint, tag string) (*TableExport, error) {
+	if _, err := s.activityService.GetActivityByID(activityID); err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	tag = strings.TrimSpace(tag)
+
+	return &TableExport{
+		Name:    fmt.Sprintf("activity_%d_artworks", activityID),
+		Columns: artworkTableColumns,
+		rows: func(language string, emit func(cells []interface{}) error) error {
+			return s.artworkRepo.StreamByActivityID(activityID, tag, func(row *repository.ArtworkRow) error {
+				return emit([]interface{}{
+					row.ID,
+					row.Title,
+					row.AuthorNickname,
+					row.AuthorEmail,
+					row.FileName,
+					row.PageCount,
+					row.Medium,
+					optionalFloat(row.WidthCM),
+					optionalFloat(row.HeightCM),
+					optionalFloat(row.DepthCM),
+					optionalInt(row.CreationYear),
+					strings.Join(row.Tags, ", "),
+					reviewStatusLabel(row.ReviewStatus, language),
+					row.RejectReason,
+					row.CreatedAt,
+				})
+			})
+		},
+	}, nil
+}
+
+// reviewQueueTableColumns are the columns of the review queue export
+var reviewQueueTableColumns = []ExportColumn{
+	{EN: "ID", ZH: "作品ID"},
+	{EN: "Activity", ZH: "活动"},
+	{EN: "Title", ZH: "标题"},
+	{EN: "Author", ZH: "作者"},
+	{EN: "Email", ZH: "邮箱"},
+	{EN: "File name", ZH: "文件名"},
+	{EN: "Pages", ZH: "页数"},
+	{EN: "Medium", ZH: "媒介"},
+	{EN: "Submitted at", ZH: "提交时间"},
+}
+
+// ReviewQueueTable prepares the export of the review queue, skipping the excluded artworks
+// as the review queue listing does for artworks claimed by other reviewers
+func (s *ExportService) ReviewQueueTable(excludeIDs []uint) *TableExport {
+	return &TableExport{
+		Name:    "review_queue",
+		Columns: reviewQueueTableColumns,
+		rows: func(language string, emit func(cells []interface{}) error) error {
+			return s.artworkRepo.StreamReviewQueue(excludeIDs, func(row *repository.ArtworkRow) error {
+				return emit([]interface{}{
+					row.ID,
+					row.ActivityName,
+					row.Title,
+					row.AuthorNickname,
+					row.AuthorEmail,
+					row.FileName,
+					row.PageCount,
+					row.Medium,
+					row.CreatedAt,
+				})
+			})
+		},
+	}
+}
+
+// userTableColumns are the columns of the user list export
+var userTableColumns = []ExportColumn{
+	{EN: "ID", ZH: "用户ID"},
+	{EN: "Email", ZH: "邮箱"},
+	{EN: "Nickname", ZH: "昵称"},
+	{EN: "Role", ZH: "角色"},
+	{EN: "Registered at", ZH: "注册时间"},
+}
+
+// UsersTable prepares the export of all users
+func (s *ExportService) UsersTable() *TableExport {
+	return &TableExport{
+		Name:    "users",
+		Columns: userTableColumns,
+		rows: func(language string, emit func(cells []interface{}) error) error {
+			return s.userRepo.Stream(func(user *models.User) error {
+				return emit([]interface{}{
+					user.ID,
+					user.Email,
+					user.Nickname,
+					roleLabel(user.Role, language),
+					user.CreatedAt,
+				})
+			})
+		},
+	}
+}
+
+// reviewStatusLabel returns the display name of a review status in the given language
+func reviewStatusLabel(status models.ReviewStatus, language string) string {
+	if language == ExportLanguageEN {
+		return string(status)
+	}
+	switch status {
+	case models.StatusPending:
+		return "待审核"
+	case models.StatusApproved:
+		return "已通过"
+	case models.StatusRejected:
+		return "已驳回"
+	}
+	return string(status)
+}
+
+// roleLabel returns the display name of a user role in the given language
+func roleLabel(role, language string) string {
+	if language == ExportLanguageEN {
+		return role
+	}
+	switch role {
+	case "admin":
+		return "管理员"
+	case "user":
+		return "普通用户"
+	}
+	return role
+}
+
+// optionalFloat turns an optional number into a table cell, leaving it empty when unset
+func optionalFloat(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// optionalInt turns an optional number into a table cell, leaving it empty when unset
+func optionalInt(v *int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -1,121 +1,493 @@
 package service
 
 import (
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/repository"
+	"art-collection-system/internal/storage"
+	"art-collection-system/internal/utils"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
 	"io"
 	"mime/multipart"
-	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/google/uuid"
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"gorm.io/gorm"
 )
 
-// ArtworkServiceInterface defines the interface for artwork service operations
-// This allows FileService to work with ArtworkService without circular dependency
-type ArtworkServiceInterface interface {
-	GetArtwork(artworkID, requesterID uint, requesterRole string) (interface{}, error)
+// Rendition names accepted by the image endpoints
+const (
+	RenditionThumb    = "thumb"
+	RenditionPreview  = "preview"
+	RenditionOriginal = "original"
+)
+
+// renditionSizes maps each downscaled rendition to the maximum length of its longest edge in pixels
+var renditionSizes = map[string]int{
+	RenditionThumb:   200,
+	RenditionPreview: 800,
+}
+
+// renditionJPEGQuality is the JPEG quality used when encoding renditions
+const renditionJPEGQuality = 85
+
+// maxStoreBlobAttempts bounds how often storeBlob retries when concurrent uploads of the same content conflict
+const maxStoreBlobAttempts = 3
+
+// maxHashCacheEntries bounds the number of content hashes FileService keeps in memory for ETags
+const maxHashCacheEntries = 10000
+
+// cachedHash is the content hash of a file, valid while its size and modification time are unchanged
+type cachedHash struct {
+	hash    string
+	size    int64
+	modTime time.Time
 }
 
 // FileService handles file storage and access operations
+// Files are kept in a storage backend; stored paths are the backend's object keys
 type FileService struct {
-	uploadPath string
+	store                storage.Storage
+	blobRepo             *repository.BlobRepository
+	keepOriginalMetadata bool
+
+	hashMu    sync.Mutex
+	hashCache map[string]cachedHash
 }
 
 // NewFileService creates a new file service instance
-func NewFileService(uploadPath string) *FileService {
+// keepOriginalMetadata controls whether metadata stripped from uploaded images is returned for storage
+func NewFileService(store storage.Storage, blobRepo *repository.BlobRepository, keepOriginalMetadata bool) *FileService {
 	return &FileService{
-		uploadPath: uploadPath,
+		store:                store,
+		blobRepo:             blobRepo,
+		keepOriginalMetadata: keepOriginalMetadata,
+		hashCache:            make(map[string]cachedHash),
 	}
 }
 
-// SaveFile saves an uploaded file to the server with a unique filename
-// File path structure: uploads/{year}/{month}/{uuid}_{original_filename}
+// StoredFile describes a file saved by SaveFile
+type StoredFile struct {
+	// BlobID is the blob holding the content; the caller owns one reference to it
+	BlobID uint
+	// Path is the object key of the stored file, relative to the storage root
+	Path string
+	// OriginalMetadata holds the metadata stripped from the image, only when keeping it is enabled
+	OriginalMetadata map[string]string
+	// ContentHash is the SHA-256 of the stored content
+	ContentHash string
+	// PerceptualHash is the dHash of the image, nil when the image could not be decoded
+	PerceptualHash *uint64
+}
+
+// SaveFile saves an uploaded file as a content-addressed blob
+// Image metadata (EXIF, GPS, XMP) is stripped and the EXIF orientation is applied before storing.
+// Content identical to an existing blob is not stored again; a reference to that blob is taken instead.
+// The caller must release the reference with ReleaseFile if it does not keep the file.
 // Requirements: 11.1, 11.2
-func (s *FileService) SaveFile(file multipart.File, filename string) (string, error) {
-	// Generate unique filename using UUID + original filename
-	uniqueFilename := fmt.Sprintf("%s_%s", uuid.New().String(), filename)
+func (s *FileService) SaveFile(file multipart.File, filename string) (*StoredFile, error) {
+	// Read the upload; its size has already been validated
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
 
-	// Create directory structure based on current year and month
-	now := time.Now()
-	year := fmt.Sprintf("%d", now.Year())
-	month := fmt.Sprintf("%02d", now.Month())
-	
-	dirPath := filepath.Join(s.uploadPath, year, month)
-	
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
-		return "", fmt.Errorf("failed to create directory: %w", err)
+	// Strip privacy-sensitive metadata before anything is stored
+	sanitized, err := utils.SanitizeImage(data)
+	if err != nil {
+		return nil, err
 	}
 
-	// Full file path
-	filePath := filepath.Join(dirPath, uniqueFilename)
+	// Decode once for both the perceptual hash and the renditions
+	// Images too large to decode safely are refused rather than stored without renditions
+	img, format, decodeErr := utils.DecodeImage(sanitized.Data)
+	if errors.Is(decodeErr, utils.ErrImageTooLarge) {
+		return nil, decodeErr
+	}
+	if decodeErr != nil {
+		img = nil
+	}
 
-	// Create the file
-	dst, err := os.Create(filePath)
+	blob, err := s.storeBlob(sanitized.Data, strings.ToLower(filepath.Ext(filename)), img, format)
 	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
+		return nil, err
 	}
-	defer dst.Close()
 
-	// Copy file content
-	if _, err := io.Copy(dst, file); err != nil {
-		return "", fmt.Errorf("failed to save file: %w", err)
+	stored := &StoredFile{
+		BlobID:      blob.ID,
+		Path:        blob.Path,
+		ContentHash: blob.Hash,
+	}
+	if s.keepOriginalMetadata && len(sanitized.Metadata) > 0 {
+		stored.OriginalMetadata = sanitized.Metadata
+	}
+	if img != nil {
+		hash := utils.ComputeDHash(img)
+		stored.PerceptualHash = &hash
 	}
 
-	// Return relative path from upload root
-	relativePath := filepath.Join(year, month, uniqueFilename)
-	return relativePath, nil
+	return stored, nil
 }
 
-// ServeFile reads and returns file content after validating permissions
-// Requirements: 11.3, 11.4, 6.4
-func (s *FileService) ServeFile(filePath string, artworkID, requesterID uint, requesterRole string, artworkService ArtworkServiceInterface) ([]byte, string, error) {
-	// Validate permissions by calling ArtworkService.GetArtwork
-	_, err := artworkService.GetArtwork(artworkID, requesterID, requesterRole)
+// ImportFile turns a stored file that predates content-addressed storage into a blob
+// and returns the blob with one reference taken; the original file is left in place
+func (s *FileService) ImportFile(filePath string) (*models.Blob, error) {
+	data, err := s.readFile(filePath)
 	if err != nil {
-		return nil, "", fmt.Errorf("permission denied: %w", err)
+		return nil, err
 	}
 
-	// Construct full file path
-	fullPath := filePath
-	if !filepath.IsAbs(fullPath) {
-		fullPath = filepath.Join(s.uploadPath, filePath)
+	img, format, err := utils.DecodeImage(data)
+	if err != nil {
+		img = nil
 	}
+	return s.storeBlob(data, strings.ToLower(filepath.Ext(filePath)), img, format)
+}
+
+// storeBlob takes a reference to the blob with the content's hash, storing the content
+// and generating its renditions from img (nil when it could not be decoded) first if no such blob exists yet
+// Blob keys have the form blobs/{hash[0:2]}/{hash[2:4]}/{hash}{ext}
+func (s *FileService) storeBlob(data []byte, ext string, img image.Image, format string) (*models.Blob, error) {
+	contentHash := utils.ComputeContentHash(data)
+	key := path.Join("blobs", contentHash[0:2], contentHash[2:4], contentHash+ext)
 
-	// Read file content
-	data, err := os.ReadFile(fullPath)
+	stored := false
+	for attempt := 0; attempt < maxStoreBlobAttempts; attempt++ {
+		blob, err := s.blobRepo.Acquire(contentHash)
+		if err == nil {
+			// A concurrent upload of the same content under another extension recorded its blob
+			// first; the object written here is not referenced by any blob
+			if stored && blob.Path != key {
+				_ = s.DeleteFile(key)
+			}
+			return blob, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to look up blob: %w", err)
+		}
+
+		// Writing is idempotent: concurrent uploads of the same content write identical bytes to the same key.
+		// The content is written again on a retry because releasing the blob that won the race deletes its object.
+		err = s.store.Put(context.Background(), key, bytes.NewReader(data), int64(len(data)), storage.PutOptions{
+			ContentType: getContentType(key),
+			SHA256:      contentHash,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save file: %w", err)
+		}
+		stored = true
+
+		// Generate downscaled renditions; a missing rendition falls back to the original
+		// and can be regenerated later with the backfill-renditions command
+		if img != nil {
+			_ = s.generateRenditions(key, img, format)
+		}
+
+		blob = &models.Blob{Hash: contentHash, Path: key, Size: int64(len(data))}
+		created, err := s.blobRepo.Create(blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record blob: %w", err)
+		}
+		if created {
+			return blob, nil
+		}
+		// Another upload of the same content recorded the blob first; take a reference to it
+	}
+	return nil, errors.New("failed to record blob: concurrent uploads of the same content kept conflicting")
+}
+
+// ReleaseFile drops a reference to a stored file
+// The file and its renditions are deleted when the last reference to its blob goes away.
+// Files stored before content-addressed storage have no blob and are deleted directly.
+func (s *FileService) ReleaseFile(blobID *uint, filePath string) error {
+	if blobID == nil {
+		return s.DeleteFile(filePath)
+	}
+
+	_, err := s.blobRepo.Release(*blobID, func(blob *models.Blob) error {
+		return s.DeleteFile(blob.Path)
+	})
+	return err
+}
+
+// VerifyBlob re-reads a blob's content and checks it against the recorded hash and size
+func (s *FileService) VerifyBlob(blob *models.Blob) error {
+	data, err := s.readFile(blob.Path)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != blob.Size {
+		return fmt.Errorf("size mismatch: recorded %d, stored %d", blob.Size, len(data))
+	}
+	if contentHash := utils.ComputeContentHash(data); contentHash != blob.Hash {
+		return fmt.Errorf("checksum mismatch: recorded %s, stored %s", blob.Hash, contentHash)
+	}
+	return nil
+}
+
+// ComputeHashes computes the SHA-256 and, when the image can be decoded, the dHash of a stored file
+func (s *FileService) ComputeHashes(filePath string) (string, *uint64, error) {
+	data, err := s.readFile(filePath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	contentHash := utils.ComputeContentHash(data)
+
+	img, _, err := utils.DecodeImage(data)
+	if err != nil {
+		return contentHash, nil, nil
+	}
+	hash := utils.ComputeDHash(img)
+	return contentHash, &hash, nil
+}
+
+// OpenedFile is a stored file opened for streaming to a client
+// The caller must Close it
+type OpenedFile struct {
+	io.ReadSeekCloser
+	// ModTime is the last modification time of the file
+	ModTime time.Time
+	// ContentType is the MIME type derived from the file extension
+	ContentType string
+	// ETag is a strong entity tag derived from the SHA-256 of the file content
+	ETag string
+}
+
+// OpenFile opens a stored file for streaming without permission checks
+// Callers are responsible for verifying that the file may be served
+func (s *FileService) OpenFile(filePath string) (*OpenedFile, error) {
+	file, info, err := s.store.Get(context.Background(), filePath)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	contentHash, err := s.contentHash(file, info)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &OpenedFile{
+		ReadSeekCloser: file,
+		ModTime:        info.ModTime,
+		ContentType:    getContentType(filePath),
+		ETag:           `"` + contentHash + `"`,
+	}, nil
+}
+
+// contentHash returns the SHA-256 of an open file, hashing it at most once per size and modification time
+// Backends that recorded the checksum at upload are trusted; otherwise the file is streamed
+// through the hash and rewound, so memory use does not depend on the file size
+func (s *FileService) contentHash(file io.ReadSeeker, info *storage.ObjectInfo) (string, error) {
+	if info.SHA256 != "" {
+		return info.SHA256, nil
+	}
+
+	s.hashMu.Lock()
+	cached, ok := s.hashCache[info.Key]
+	s.hashMu.Unlock()
+	if ok && cached.size == info.Size && cached.modTime.Equal(info.ModTime) {
+		return cached.hash, nil
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
 	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
 
-	// Determine content type based on file extension
-	contentType := getContentType(fullPath)
+	s.hashMu.Lock()
+	// Stored files are immutable, so the cache only grows with the number of files served;
+	// drop it wholesale once it gets large rather than tracking recency
+	if len(s.hashCache) >= maxHashCacheEntries {
+		s.hashCache = make(map[string]cachedHash)
+	}
+	s.hashCache[info.Key] = cachedHash{hash: hash, size: info.Size, modTime: info.ModTime}
+	s.hashMu.Unlock()
 
-	return data, contentType, nil
+	return hash, nil
 }
 
-// DeleteFile deletes a physical file from the server
+// DeleteFile deletes a physical file and its renditions from the server
 // Requirements: 4.5
 func (s *FileService) DeleteFile(filePath string) error {
-	// Construct full file path
-	fullPath := filePath
-	if !filepath.IsAbs(fullPath) {
-		fullPath = filepath.Join(s.uploadPath, filePath)
+	ctx := context.Background()
+
+	// Delete renditions first; they are derived data and may not exist
+	for _, renditionPath := range RenditionPaths(filePath) {
+		_ = s.store.Delete(ctx, renditionPath)
+	}
+
+	// Delete the file; a missing file is considered already deleted
+	return s.store.Delete(ctx, filePath)
+}
+
+// IsValidRendition reports whether name is an accepted rendition name
+func IsValidRendition(name string) bool {
+	if name == RenditionOriginal {
+		return true
+	}
+	_, ok := renditionSizes[name]
+	return ok
+}
+
+// ResolveRendition returns the stored path of the requested rendition of a file
+// It falls back to the original when the rendition has not been generated, e.g.
+// because the original is already smaller than the rendition size
+func (s *FileService) ResolveRendition(filePath, name string) string {
+	if _, ok := renditionSizes[name]; !ok {
+		return filePath
+	}
+
+	renditionPath := renditionFilePath(filePath, name)
+	if _, err := s.store.Stat(context.Background(), renditionPath); err != nil {
+		return filePath
 	}
+	return renditionPath
+}
 
-	// Delete the file
-	if err := os.Remove(fullPath); err != nil {
-		// If file doesn't exist, consider it already deleted (not an error)
-		if os.IsNotExist(err) {
-			return nil
+// GenerateRenditions creates the downscaled renditions of a stored JPEG, PNG or GIF image
+// Renditions larger than or equal to the original are skipped. Existing renditions are overwritten.
+func (s *FileService) GenerateRenditions(filePath string) error {
+	data, err := s.readFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	img, format, err := utils.DecodeImage(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	return s.generateRenditions(filePath, img, format)
+}
+
+// generateRenditions writes the downscaled renditions of an already decoded image
+func (s *FileService) generateRenditions(filePath string, img image.Image, format string) error {
+	if format != "jpeg" && format != "png" && format != "gif" {
+		return fmt.Errorf("unsupported image format: %s", format)
+	}
+
+	bounds := img.Bounds()
+	for name, maxEdge := range renditionSizes {
+		width, height := fitWithin(bounds.Dx(), bounds.Dy(), maxEdge)
+		if width >= bounds.Dx() && height >= bounds.Dy() {
+			// The original is already small enough; it is served instead
+			continue
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+		if err := s.writeRendition(renditionFilePath(filePath, name), dst); err != nil {
+			return err
 		}
-		return fmt.Errorf("failed to delete file: %w", err)
 	}
 
 	return nil
 }
 
+// writeRendition encodes a rendition image to the given stored path
+func (s *FileService) writeRendition(renditionPath string, img image.Image) error {
+	var buf bytes.Buffer
+	var err error
+	if strings.HasSuffix(renditionPath, ".jpg") {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: renditionJPEGQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode rendition: %w", err)
+	}
+
+	err = s.store.Put(context.Background(), renditionPath, &buf, int64(buf.Len()), storage.PutOptions{
+		ContentType: getContentType(renditionPath),
+		SHA256:      utils.ComputeContentHash(buf.Bytes()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save rendition: %w", err)
+	}
+
+	return nil
+}
+
+// OpenReader opens a stored file for sequential reading without hashing it
+// The caller must close the returned reader
+func (s *FileService) OpenReader(filePath string) (io.ReadCloser, error) {
+	file, _, err := s.store.Get(context.Background(), filePath)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// readFile reads the whole content of a stored file
+func (s *FileService) readFile(filePath string) ([]byte, error) {
+	file, _, err := s.store.Get(context.Background(), filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return data, nil
+}
+
+// RenditionPaths returns the stored paths of all renditions a file may have
+func RenditionPaths(filePath string) []string {
+	paths := make([]string, 0, len(renditionSizes))
+	for name := range renditionSizes {
+		paths = append(paths, renditionFilePath(filePath, name))
+	}
+	return paths
+}
+
+// renditionFilePath derives the stored path of a rendition from the original's path
+// e.g. 2025/10/{uuid}_photo.png -> 2025/10/{uuid}_photo.thumb.png
+// JPEG originals produce JPEG renditions; PNG and GIF originals produce PNG renditions
+func renditionFilePath(filePath, name string) string {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	base := strings.TrimSuffix(filePath, filepath.Ext(filePath))
+
+	renditionExt := ".png"
+	if ext == ".jpg" || ext == ".jpeg" {
+		renditionExt = ".jpg"
+	}
+	return base + "." + name + renditionExt
+}
+
+// fitWithin scales width and height so that the longest edge is at most maxEdge, keeping the aspect ratio
+func fitWithin(width, height, maxEdge int) (int, int) {
+	if width <= maxEdge && height <= maxEdge {
+		return width, height
+	}
+	if width >= height {
+		return maxEdge, max(1, height*maxEdge/width)
+	}
+	return max(1, width*maxEdge/height), maxEdge
+}
+
 // getContentType determines the MIME type based on file extension
 func getContentType(filename string) string {
 	ext := filepath.Ext(filename)
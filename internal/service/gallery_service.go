@@ -0,0 +1,153 @@
+package service
+
+import (
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/repository"
+	"errors"
+	"time"
+)
+
+// GalleryArtwork is the public view of an approved artwork shown in an activity gallery
+// It deliberately omits private author details such as the email address
+type GalleryArtwork struct {
+	ID           uint      `json:"id"`
+	ActivityID   uint      `json:"activity_id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Medium       string    `json:"medium"`
+	WidthCM      *float64  `json:"width_cm"`
+	HeightCM     *float64  `json:"height_cm"`
+	DepthCM      *float64  `json:"depth_cm"`
+	CreationYear *int      `json:"creation_year"`
+	Tags         []string  `json:"tags"`
+	PageCount    int       `json:"page_count"`
+	VoteCount    int64     `json:"vote_count"`
+	AuthorID     uint      `json:"author_id"`
+	AuthorName   string    `json:"author_name"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// newGalleryArtwork builds the public view of an artwork
+func newGalleryArtwork(artwork *models.Artwork) GalleryArtwork {
+	return GalleryArtwork{
+		ID:           artwork.ID,
+		ActivityID:   artwork.ActivityID,
+		Title:        artwork.Title,
+		Description:  artwork.Description,
+		Medium:       artwork.Medium,
+		WidthCM:      artwork.WidthCM,
+		HeightCM:     artwork.HeightCM,
+		DepthCM:      artwork.DepthCM,
+		CreationYear: artwork.CreationYear,
+		Tags:         artwork.Tags,
+		PageCount:    artwork.PageCount,
+		VoteCount:    artwork.VoteCount,
+		AuthorID:     artwork.UserID,
+		AuthorName:   artwork.User.Nickname,
+		CreatedAt:    artwork.CreatedAt,
+	}
+}
+
+// GalleryService handles the public gallery of approved artworks per activity
+type GalleryService struct {
+	artworkRepo     *repository.ArtworkRepository
+	activityService *ActivityService
+	voteService     *VoteService
+}
+
+// NewGalleryService creates a new gallery service instance
+func NewGalleryService(artworkRepo *repository.ArtworkRepository, activityService *ActivityService, voteService *VoteService) *GalleryService {
+	return &GalleryService{
+		artworkRepo:     artworkRepo,
+		activityService: activityService,
+		voteService:     voteService,
+	}
+}
+
+// openGallery retrieves an activity and verifies that its public gallery is open
+func (s *GalleryService) openGallery(activityID uint) (*models.Activity, error) {
+	activity, err := s.activityService.GetActivityByID(activityID)
+	if err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if !activity.IsGalleryOpen(time.Now()) {
+		return nil, errors.New("该活动的作品展示尚未开放")
+	}
+	return activity, nil
+}
+
+// ListGallery retrieves the approved artworks of an activity's public gallery with pagination
+func (s *GalleryService) ListGallery(activityID uint, page, pageSize int) ([]GalleryArtwork, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	activity, err := s.openGallery(activityID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	artworks, total, err := s.artworkRepo.GetByActivityIDAndStatusWithPagination(activityID, models.StatusApproved, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	counts := s.voteService.GetCounts(activity, artworks)
+	items := make([]GalleryArtwork, 0, len(artworks))
+	for i := range artworks {
+		item := newGalleryArtwork(&artworks[i])
+		item.VoteCount = counts[item.ID]
+		items = append(items, item)
+	}
+
+	return items, total, nil
+}
+
+// GetGalleryArtwork retrieves a single approved artwork from an activity's public gallery
+func (s *GalleryService) GetGalleryArtwork(activityID, artworkID uint) (*GalleryArtwork, error) {
+	activity, artwork, err := s.getApprovedArtwork(activityID, artworkID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := newGalleryArtwork(artwork)
+	item.VoteCount = s.voteService.GetCounts(activity, []models.Artwork{*artwork})[artwork.ID]
+	return &item, nil
+}
+
+// GetGalleryImagePath retrieves the stored file path of a page of an approved artwork in an
+// activity's public gallery; page 1 is the artwork's own file
+func (s *GalleryService) GetGalleryImagePath(activityID, artworkID uint, page int) (string, error) {
+	_, artwork, err := s.getApprovedArtwork(activityID, artworkID)
+	if err != nil {
+		return "", err
+	}
+	if page == 1 {
+		return artwork.FilePath, nil
+	}
+
+	artworkPage, err := s.artworkRepo.GetPage(artworkID, page)
+	if err != nil {
+		return "", errors.New("作品页面不存在")
+	}
+	return artworkPage.FilePath, nil
+}
+
+// getApprovedArtwork retrieves an artwork that is approved and belongs to an open gallery,
+// together with its activity
+func (s *GalleryService) getApprovedArtwork(activityID, artworkID uint) (*models.Activity, *models.Artwork, error) {
+	activity, err := s.openGallery(activityID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	artwork, err := s.artworkRepo.GetByIDWithUser(artworkID)
+	if err != nil || artwork.ActivityID != activityID || artwork.ReviewStatus != models.StatusApproved {
+		return nil, nil, errors.New("作品不存在")
+	}
+
+	return activity, artwork, nil
+}
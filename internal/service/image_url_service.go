@@ -0,0 +1,154 @@
+package service
+
+import (
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Unauthenticated routes that serve images through signed URLs
+const (
+	signedImagePath     = "/api/v1/artworks/%d/signed-image"
+	signedPageImagePath = "/api/v1/artworks/%d/pages/%d/signed-image"
+)
+
+// ImageURLService issues and verifies signed, expiring artwork image URLs
+type ImageURLService struct {
+	ttl time.Duration
+}
+
+// NewImageURLService creates a new image URL service instance
+// Signed URLs stay valid for at least ttl and at most twice ttl
+func NewImageURLService(ttl time.Duration) *ImageURLService {
+	return &ImageURLService{
+		ttl: ttl,
+	}
+}
+
+// SignArtwork fills the signed image URLs of an artwork and of its loaded pages
+// Callers must have already checked that the requester may view the artwork
+func (s *ImageURLService) SignArtwork(artwork *models.Artwork) {
+	// Round the expiry to a ttl boundary so repeated responses reuse the same URLs,
+	// which lets browsers cache the images instead of refetching them on every page load
+	expiresAt := time.Now().Truncate(s.ttl).Add(2 * s.ttl)
+
+	artwork.ImageURLs = s.signedURLs(artwork.ID, 1, fileVersion(artwork.ContentHash, artwork.FilePath), expiresAt)
+	for i := range artwork.Pages {
+		page := &artwork.Pages[i]
+		page.ImageURLs = s.signedURLs(artwork.ID, page.Page, fileVersion(page.ContentHash, page.FilePath), expiresAt)
+	}
+}
+
+// SignArtworks fills the signed image URLs of each artwork in place
+func (s *ImageURLService) SignArtworks(artworks []models.Artwork) {
+	for i := range artworks {
+		s.SignArtwork(&artworks[i])
+	}
+}
+
+// SignJudgingArtwork fills the signed image URLs of the judges' view of an artwork and of its pages
+// Callers must have already checked that the requester judges the artwork's activity
+func (s *ImageURLService) SignJudgingArtwork(artwork *JudgingArtwork) {
+	expiresAt := time.Now().Truncate(s.ttl).Add(2 * s.ttl)
+
+	artwork.ImageURLs = s.signedURLs(artwork.ID, 1, artwork.version, expiresAt)
+	for i := range artwork.Pages {
+		page := &artwork.Pages[i]
+		page.ImageURLs = s.signedURLs(artwork.ID, page.Page, page.version, expiresAt)
+	}
+}
+
+// SignJudgingEntries fills the signed image URLs of each judging sheet entry in place
+func (s *ImageURLService) SignJudgingEntries(entries []JudgingEntry) {
+	for i := range entries {
+		s.SignJudgingArtwork(entries[i].Artwork)
+	}
+}
+
+// SignScoringResults fills the signed image URLs of the artwork of each scoring result in place
+func (s *ImageURLService) SignScoringResults(results []ScoringResult) {
+	for i := range results {
+		switch artwork := results[i].Artwork.(type) {
+		case *models.Artwork:
+			s.SignArtwork(artwork)
+		case *JudgingArtwork:
+			s.SignJudgingArtwork(artwork)
+		}
+	}
+}
+
+// SignReviewQueueItems fills the signed image URLs of each review queue item in place
+func (s *ImageURLService) SignReviewQueueItems(items []ReviewQueueItem) {
+	for i := range items {
+		s.SignArtwork(&items[i].Artwork)
+	}
+}
+
+// Verify checks the signature and expiry of a signed image URL and returns the remaining validity
+func (s *ImageURLService) Verify(artworkID uint, page int, size, version, expires, signature string) (time.Duration, error) {
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return 0, utils.ErrInvalidImageSignature
+	}
+
+	if err := utils.VerifyImageURL(artworkID, page, size, version, expiresUnix, signature); err != nil {
+		return 0, err
+	}
+	return time.Until(time.Unix(expiresUnix, 0)), nil
+}
+
+// fileVersion identifies the file an image URL points at, so that replacing or reordering
+// the file changes the URL instead of leaving browsers with a cached copy of the old image
+// Files stored before content hashes were recorded fall back to a hash of their path
+func fileVersion(contentHash, filePath string) string {
+	if contentHash == "" {
+		sum := sha256.Sum256([]byte(filePath))
+		contentHash = hex.EncodeToString(sum[:])
+	}
+	return contentHash[:16]
+}
+
+// signedURLs builds the signed URLs of every rendition of one page
+// It returns nil when URLs cannot be signed
+func (s *ImageURLService) signedURLs(artworkID uint, page int, version string, expiresAt time.Time) *models.ArtworkImageURLs {
+	urls := &models.ArtworkImageURLs{ExpiresAt: expiresAt}
+	for size, target := range map[string]*string{
+		RenditionThumb:    &urls.Thumb,
+		RenditionPreview:  &urls.Preview,
+		RenditionOriginal: &urls.Original,
+	} {
+		signedURL, err := s.signedURL(artworkID, page, size, version, expiresAt.Unix())
+		if err != nil {
+			// Without a signing key the authenticated image endpoint still works
+			return nil
+		}
+		*target = signedURL
+	}
+	return urls
+}
+
+// signedURL builds the signed URL of one rendition of one page
+// Page 1 is the artwork's own file and keeps the shorter path
+func (s *ImageURLService) signedURL(artworkID uint, page int, size, version string, expires int64) (string, error) {
+	signature, err := utils.SignImageURL(artworkID, page, size, version, expires)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("size", size)
+	query.Set("v", version)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", signature)
+
+	path := fmt.Sprintf(signedImagePath, artworkID)
+	if page != 1 {
+		path = fmt.Sprintf(signedPageImagePath, artworkID, page)
+	}
+	return path + "?" + query.Encode(), nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// jobLockKeyPrefix is the Redis key prefix of the lock held while a background job runs
+// Key format: job:lock:{job}
+const jobLockKeyPrefix = "job:lock:"
+
+// JobRunner runs periodic background jobs on one server instance at a time
+// Every instance runs the same tickers; the instance that takes a job's lock runs it and the others skip that run
+type JobRunner struct {
+	redis *redis.Client
+}
+
+// NewJobRunner creates a new job runner instance
+func NewJobRunner(redisClient *redis.Client) *JobRunner {
+	return &JobRunner{redis: redisClient}
+}
+
+// RunExclusive runs a job unless another instance is running it
+// ttl bounds how long a crashed instance keeps the job locked; it should cover a whole run
+func (r *JobRunner) RunExclusive(job string, ttl time.Duration, run func() error) error {
+	lock, err := acquireRedisLock(context.Background(), r.redis, jobLockKeyPrefix+job, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to lock job %s: %w", job, err)
+	}
+	if lock == nil {
+		return nil
+	}
+	defer lock.Release()
+
+	return run()
+}
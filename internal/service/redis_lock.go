@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseLockScript deletes a key only if it still holds the given value, so a lock or lease
+// that expired and was taken over by another holder is kept
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// redisLock is a lock held in Redis by one holder at a time
+// The lock expires after its ttl, so a holder that crashes does not keep it forever
+type redisLock struct {
+	client *redis.Client
+	key    string
+	token  string
+}
+
+// acquireRedisLock takes the lock at key for ttl
+// It returns nil without an error when another holder has the lock
+func acquireRedisLock(ctx context.Context, client *redis.Client, key string, ttl time.Duration) (*redisLock, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+	acquired, err := client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, nil
+	}
+	return &redisLock{client: client, key: key, token: token}, nil
+}
+
+// Release gives up the lock unless it has expired and been taken by another holder
+func (l *redisLock) Release() {
+	_ = releaseLockScript.Run(context.Background(), l.client, []string{l.key}, l.token).Err()
+}
+
+// newLockToken generates a random token identifying the holder of a lock
+func newLockToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,418 @@
+package service
+
+import (
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/storage"
+	"art-collection-system/internal/utils"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// resumableUploadKeyPrefix is the Redis key prefix of an upload's state, stored as JSON
+	// Key format: upload:resumable:{uploadID}; the key expires with the upload
+	resumableUploadKeyPrefix = "upload:resumable:"
+
+	// resumableLockKeyPrefix is the Redis key prefix of the lock held while an upload is written to
+	resumableLockKeyPrefix = "upload:resumable-lock:"
+
+	// resumableLockTTL bounds how long a crashed request can keep an upload locked
+	// It must cover a whole chunk arriving over a slow connection
+	resumableLockTTL = 30 * time.Minute
+
+	// ResumableUploadPrefix is the storage key prefix of the parts of unfinished resumable uploads
+	// Parts are stored as resumable/{uploadID}/{offset}
+	ResumableUploadPrefix = "resumable/"
+)
+
+var (
+	// ErrUploadNotFound is returned for unknown, expired or foreign uploads
+	ErrUploadNotFound = errors.New("上传不存在或已过期")
+	// ErrUploadOffsetMismatch is returned when a chunk does not continue where the upload left off
+	ErrUploadOffsetMismatch = errors.New("上传偏移量不匹配")
+	// ErrUploadLocked is returned while another request is writing to the same upload
+	ErrUploadLocked = errors.New("该上传正在被其他请求写入")
+	// ErrUploadLengthExceeded is returned when a chunk goes past the declared upload length
+	ErrUploadLengthExceeded = errors.New("上传内容超过声明的文件大小")
+	// ErrUploadIncomplete is returned when completing an upload that has not received all its data
+	ErrUploadIncomplete = errors.New("上传尚未完成")
+)
+
+// ResumableUpload is the state of a resumable upload
+type ResumableUpload struct {
+	ID         string          `json:"id"`
+	UserID     uint            `json:"user_id"`
+	ActivityID uint            `json:"activity_id"`
+	Filename   string          `json:"filename"`
+	Metadata   ArtworkMetadata `json:"metadata"`
+	Draft      bool            `json:"draft"`
+	Length     int64           `json:"length"`
+	Offset     int64           `json:"offset"`
+	ExpiresAt  time.Time       `json:"expires_at"`
+}
+
+// ResumableUploadService stores uploads that arrive in several chunks, possibly over
+// several connections. Each chunk is stored as a temporary part; once all data has
+// arrived the parts are assembled and go through the regular artwork upload.
+// Uploads expire when no data arrives for the configured time.
+type ResumableUploadService struct {
+	redis          *redis.Client
+	store          storage.Storage
+	artworkService *ArtworkService
+	maxSize        int64
+	ttl            time.Duration
+}
+
+// NewResumableUploadService creates a new resumable upload service instance
+// maxSize is the configured upload size limit and ttl how long an idle upload is kept
+func NewResumableUploadService(redisClient *redis.Client, store storage.Storage, artworkService *ArtworkService, maxSize int64, ttl time.Duration) *ResumableUploadService {
+	return &ResumableUploadService{
+		redis:          redisClient,
+		store:          store,
+		artworkService: artworkService,
+		maxSize:        maxSize,
+		ttl:            ttl,
+	}
+}
+
+// MaxSize returns the largest upload length accepted
+func (s *ResumableUploadService) MaxSize() int64 {
+	if s.maxSize <= 0 {
+		return utils.MaxFileSize
+	}
+	return s.maxSize
+}
+
+// Create starts a resumable upload of length bytes, which is saved as a draft when draft is set
+// The file name, size, metadata, activity and upload limit are checked before any data is accepted
+func (s *ResumableUploadService) Create(userID, activityID uint, filename string, length int64, metadata ArtworkMetadata, draft bool) (*ResumableUpload, error) {
+	if length <= 0 {
+		return nil, errors.New("无效的文件大小")
+	}
+	if err := utils.ValidateImageUploadInfo(filename, length, s.maxSize); err != nil {
+		return nil, err
+	}
+	if err := s.artworkService.PrecheckUpload(userID, activityID, filename, metadata, draft); err != nil {
+		return nil, err
+	}
+
+	id, err := newUploadID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate upload ID: %w", err)
+	}
+
+	upload := &ResumableUpload{
+		ID:         id,
+		UserID:     userID,
+		ActivityID: activityID,
+		Filename:   filename,
+		Metadata:   metadata,
+		Draft:      draft,
+		Length:     length,
+	}
+	if err := s.save(context.Background(), upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Get retrieves an upload of the user
+func (s *ResumableUploadService) Get(userID uint, id string) (*ResumableUpload, error) {
+	return s.load(context.Background(), userID, id)
+}
+
+// WriteChunk appends data read from body to an upload at offset, which must be the upload's
+// current offset. Whatever arrives before body fails is kept, so a client whose connection
+// drops can resume from the new offset. It returns the upload with its new offset.
+func (s *ResumableUploadService) WriteChunk(userID uint, id string, offset int64, body io.Reader) (*ResumableUpload, error) {
+	ctx := context.Background()
+	unlock, err := s.lock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	upload, err := s.load(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if offset != upload.Offset {
+		return upload, ErrUploadOffsetMismatch
+	}
+
+	// Buffer the chunk on local disk first, since storage needs its size up front
+	tmp, err := os.CreateTemp("", "resumable-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to buffer upload chunk: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	remaining := upload.Length - upload.Offset
+	written, readErr := io.Copy(tmp, io.LimitReader(body, remaining+1))
+	if written > remaining {
+		return upload, ErrUploadLengthExceeded
+	}
+	if written == 0 {
+		if readErr != nil {
+			return upload, readErr
+		}
+		return upload, nil
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to buffer upload chunk: %w", err)
+	}
+	if err := s.store.Put(ctx, partKey(upload.ID, upload.Offset), tmp, written, storage.PutOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to store upload chunk: %w", err)
+	}
+
+	upload.Offset += written
+	if err := s.save(ctx, upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Complete assembles a fully received upload and creates the artwork through the regular
+// upload pipeline. The upload and its parts are removed once the artwork is created; when
+// the artwork is refused the upload is kept until it expires, so completion can be retried.
+func (s *ResumableUploadService) Complete(userID uint, id string) (*models.Artwork, error) {
+	ctx := context.Background()
+	unlock, err := s.lock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	upload, err := s.load(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Offset != upload.Length {
+		return nil, ErrUploadIncomplete
+	}
+
+	data, err := s.assemble(ctx, upload)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &assembledFile{Reader: bytes.NewReader(data)}
+	if err := utils.ValidateImageReader(upload.Filename, int64(len(data)), file, s.maxSize); err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	artwork, err := s.artworkService.UploadArtwork(upload.UserID, upload.ActivityID, []UploadedFile{{File: file, Filename: upload.Filename}}, upload.Metadata, upload.Draft)
+	if err != nil {
+		return nil, err
+	}
+
+	s.remove(ctx, upload.ID)
+	return artwork, nil
+}
+
+// Delete cancels an upload of the user and removes its parts
+func (s *ResumableUploadService) Delete(userID uint, id string) error {
+	ctx := context.Background()
+	unlock, err := s.lock(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := s.load(ctx, userID, id); err != nil {
+		return err
+	}
+	s.remove(ctx, id)
+	return nil
+}
+
+// CleanupExpired deletes the parts of uploads that have expired and returns how many
+// uploads were cleaned up. The upload state expires by itself in Redis.
+func (s *ResumableUploadService) CleanupExpired(ctx context.Context) (int, error) {
+	expired := make(map[string]bool)
+	var keys []string
+	err := s.store.List(ctx, ResumableUploadPrefix, func(info storage.ObjectInfo) error {
+		id, _, found := strings.Cut(strings.TrimPrefix(info.Key, ResumableUploadPrefix), "/")
+		if !found {
+			return nil
+		}
+		if _, checked := expired[id]; !checked {
+			exists, err := s.redis.Exists(ctx, resumableUploadKeyPrefix+id).Result()
+			if err != nil {
+				return err
+			}
+			expired[id] = exists == 0
+		}
+		if expired[id] {
+			keys = append(keys, info.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return 0, err
+		}
+	}
+
+	count := 0
+	for _, isExpired := range expired {
+		if isExpired {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// assemble reads the parts of an upload in order and checks that they cover it exactly
+func (s *ResumableUploadService) assemble(ctx context.Context, upload *ResumableUpload) ([]byte, error) {
+	prefix := ResumableUploadPrefix + upload.ID + "/"
+	var parts []storage.ObjectInfo
+	err := s.store.List(ctx, prefix, func(info storage.ObjectInfo) error {
+		parts = append(parts, info)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upload parts: %w", err)
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Key < parts[j].Key })
+
+	data := make([]byte, 0, upload.Length)
+	for _, part := range parts {
+		offset, err := strconv.ParseInt(strings.TrimPrefix(part.Key, prefix), 10, 64)
+		if err != nil || offset != int64(len(data)) {
+			return nil, fmt.Errorf("upload %s has a gap or overlap at part %s", upload.ID, part.Key)
+		}
+
+		reader, _, err := s.store.Get(ctx, part.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upload part: %w", err)
+		}
+		chunk, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upload part: %w", err)
+		}
+		data = append(data, chunk...)
+	}
+
+	if int64(len(data)) != upload.Length {
+		return nil, fmt.Errorf("upload %s has %d of %d bytes stored", upload.ID, len(data), upload.Length)
+	}
+	return data, nil
+}
+
+// load reads the state of an upload, hiding uploads of other users
+func (s *ResumableUploadService) load(ctx context.Context, userID uint, id string) (*ResumableUpload, error) {
+	// IDs are also used in storage keys; refuse anything that is not one of ours
+	if !isUploadToken(id) {
+		return nil, ErrUploadNotFound
+	}
+
+	value, err := s.redis.Get(ctx, resumableUploadKeyPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load upload: %w", err)
+	}
+
+	var upload ResumableUpload
+	if err := json.Unmarshal(value, &upload); err != nil {
+		return nil, fmt.Errorf("failed to load upload: %w", err)
+	}
+	if upload.UserID != userID {
+		return nil, ErrUploadNotFound
+	}
+	return &upload, nil
+}
+
+// save stores the state of an upload and restarts its expiry
+func (s *ResumableUploadService) save(ctx context.Context, upload *ResumableUpload) error {
+	upload.ExpiresAt = time.Now().Add(s.ttl)
+	value, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	if err := s.redis.Set(ctx, resumableUploadKeyPrefix+upload.ID, value, s.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save upload: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the state and parts of an upload
+// Parts left behind by a failed delete are removed by CleanupExpired
+func (s *ResumableUploadService) remove(ctx context.Context, id string) {
+	_ = s.redis.Del(ctx, resumableUploadKeyPrefix+id).Err()
+	_ = s.store.List(ctx, ResumableUploadPrefix+id+"/", func(info storage.ObjectInfo) error {
+		_ = s.store.Delete(ctx, info.Key)
+		return nil
+	})
+}
+
+// lock takes the write lock of an upload and returns the function releasing it
+func (s *ResumableUploadService) lock(ctx context.Context, id string) (func(), error) {
+	lock, err := acquireRedisLock(ctx, s.redis, resumableLockKeyPrefix+id, resumableLockTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock upload: %w", err)
+	}
+	if lock == nil {
+		return nil, ErrUploadLocked
+	}
+	return lock.Release, nil
+}
+
+// newUploadID generates a random upload ID
+func newUploadID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// isUploadToken reports whether id has the form generated by newUploadID
+func isUploadToken(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// partKey returns the storage key of the part of an upload starting at offset
+// Offsets are zero-padded so that parts list in order
+func partKey(id string, offset int64) string {
+	return fmt.Sprintf("%s%s/%020d", ResumableUploadPrefix, id, offset)
+}
+
+// assembledFile is an assembled upload passed to the artwork upload pipeline as a multipart.File
+type assembledFile struct {
+	*bytes.Reader
+}
+
+// Close implements multipart.File
+func (f *assembledFile) Close() error {
+	return nil
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// reviewLeaseKeyPrefix is the Redis key prefix of a single artwork lease, value is the reviewer ID
+	// Key format: review:lease:{artworkID}
+	reviewLeaseKeyPrefix = "review:lease:"
+
+	// reviewLeaseIndexKey is a sorted set of leased artwork IDs scored by lease expiry (unix seconds)
+	reviewLeaseIndexKey = "review:leases"
+)
+
+// renewLeaseScript extends a lease held by the given reviewer, or takes the lease again if it
+// expired in the meantime; it returns 0 when another reviewer holds the lease
+var renewLeaseScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if owner == false then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// ReviewLeaseService leases pending artworks to reviewers so that concurrent
+// reviewers working the review queue do not open the same items
+type ReviewLeaseService struct {
+	redis        *redis.Client
+	artworkRepo  *repository.ArtworkRepository
+	ttl          time.Duration
+	maxClaimSize int
+}
+
+// NewReviewLeaseService creates a new review lease service instance
+func NewReviewLeaseService(redisClient *redis.Client, artworkRepo *repository.ArtworkRepository, ttl time.Duration, maxClaimSize int) *ReviewLeaseService {
+	return &ReviewLeaseService{
+		redis:        redisClient,
+		artworkRepo:  artworkRepo,
+		ttl:          ttl,
+		maxClaimSize: maxClaimSize,
+	}
+}
+
+// ClaimNext leases up to count of the oldest pending artworks not leased by other reviewers
+// Artworks already leased by the reviewer are kept and their lease is renewed
+func (s *ReviewLeaseService) ClaimNext(reviewerID uint, count int) ([]models.Artwork, time.Time, error) {
+	if count <= 0 {
+		count = 10
+	}
+	if count > s.maxClaimSize {
+		count = s.maxClaimSize
+	}
+
+	ctx := context.Background()
+	owner := strconv.FormatUint(uint64(reviewerID), 10)
+	expiresAt := time.Now().Add(s.ttl)
+
+	leased, err := s.activeLeases(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	// Skip every artwork currently leased by anyone; our own leases are renewed below
+	excludeIDs := make([]uint, 0, len(leased))
+	claimedIDs := make([]uint, 0, count)
+	for artworkID, leaseOwner := range leased {
+		excludeIDs = append(excludeIDs, artworkID)
+		if leaseOwner == owner && len(claimedIDs) < count {
+			// The lease may have expired since it was read and been claimed by another reviewer
+			renewed, err := renewLeaseScript.Run(ctx, s.redis, []string{leaseKey(artworkID)}, owner, s.ttl.Milliseconds()).Int()
+			if err != nil {
+				return nil, time.Time{}, fmt.Errorf("failed to renew review lease: %w", err)
+			}
+			if renewed == 1 {
+				claimedIDs = append(claimedIDs, artworkID)
+			}
+		}
+	}
+
+	for len(claimedIDs) < count {
+		candidates, err := s.artworkRepo.GetPendingIDs(excludeIDs, count-len(claimedIDs))
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if len(candidates) == 0 {
+			break
+		}
+
+		for _, artworkID := range candidates {
+			excludeIDs = append(excludeIDs, artworkID)
+
+			// Atomic set-if-absent; losing the race means another reviewer claimed it first
+			ok, err := s.redis.SetNX(ctx, leaseKey(artworkID), owner, s.ttl).Result()
+			if err != nil {
+				return nil, time.Time{}, fmt.Errorf("failed to claim artwork: %w", err)
+			}
+			if ok {
+				claimedIDs = append(claimedIDs, artworkID)
+			}
+		}
+	}
+
+	if len(claimedIDs) == 0 {
+		return []models.Artwork{}, expiresAt, nil
+	}
+
+	// Track the leases so other reviewers can hide them from the review queue
+	members := make([]redis.Z, 0, len(claimedIDs))
+	for _, artworkID := range claimedIDs {
+		members = append(members, redis.Z{Score: float64(expiresAt.Unix()), Member: artworkID})
+	}
+	if err := s.redis.ZAdd(ctx, reviewLeaseIndexKey, members...).Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to index review leases: %w", err)
+	}
+
+	artworks, err := s.artworkRepo.GetByIDsWithRelations(claimedIDs)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return artworks, expiresAt, nil
+}
+
+// GetClaimed retrieves the artworks currently leased by the reviewer
+func (s *ReviewLeaseService) GetClaimed(reviewerID uint) ([]models.Artwork, error) {
+	leased, err := s.activeLeases(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	owner := strconv.FormatUint(uint64(reviewerID), 10)
+	ids := make([]uint, 0)
+	for artworkID, leaseOwner := range leased {
+		if leaseOwner == owner {
+			ids = append(ids, artworkID)
+		}
+	}
+
+	return s.artworkRepo.GetByIDsWithRelations(ids)
+}
+
+// LeasedByOthers returns the IDs of artworks currently leased by reviewers other than the given one
+func (s *ReviewLeaseService) LeasedByOthers(reviewerID uint) ([]uint, error) {
+	leased, err := s.activeLeases(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	owner := strconv.FormatUint(uint64(reviewerID), 10)
+	ids := make([]uint, 0, len(leased))
+	for artworkID, leaseOwner := range leased {
+		if leaseOwner != owner {
+			ids = append(ids, artworkID)
+		}
+	}
+	return ids, nil
+}
+
+// EnsureNotLeasedByOthers returns an error if any of the artworks is leased by another reviewer
+func (s *ReviewLeaseService) EnsureNotLeasedByOthers(reviewerID uint, artworkIDs []uint) error {
+	ctx := context.Background()
+	owner := strconv.FormatUint(uint64(reviewerID), 10)
+
+	for _, artworkID := range artworkIDs {
+		leaseOwner, err := s.redis.Get(ctx, leaseKey(artworkID)).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check review lease: %w", err)
+		}
+		if leaseOwner != owner {
+			return fmt.Errorf("作品已被其他审核员领取: %d", artworkID)
+		}
+	}
+	return nil
+}
+
+// Release releases the reviewer's own leases on the given artworks and returns how many were released
+func (s *ReviewLeaseService) Release(reviewerID uint, artworkIDs []uint) (int, error) {
+	if len(artworkIDs) == 0 {
+		return 0, errors.New("作品ID列表不能为空")
+	}
+
+	ctx := context.Background()
+	owner := strconv.FormatUint(uint64(reviewerID), 10)
+
+	released := 0
+	for _, artworkID := range artworkIDs {
+		deleted, err := releaseLockScript.Run(ctx, s.redis, []string{leaseKey(artworkID)}, owner).Int()
+		if err != nil {
+			return released, fmt.Errorf("failed to release review lease: %w", err)
+		}
+		if deleted > 0 {
+			released++
+			s.redis.ZRem(ctx, reviewLeaseIndexKey, artworkID)
+		}
+	}
+
+	return released, nil
+}
+
+// ReleaseReviewed drops the leases of artworks that have been reviewed, regardless of holder
+func (s *ReviewLeaseService) ReleaseReviewed(artworkIDs []uint) error {
+	if len(artworkIDs) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	keys := make([]string, 0, len(artworkIDs))
+	members := make([]interface{}, 0, len(artworkIDs))
+	for _, artworkID := range artworkIDs {
+		keys = append(keys, leaseKey(artworkID))
+		members = append(members, artworkID)
+	}
+
+	pipe := s.redis.Pipeline()
+	pipe.Del(ctx, keys...)
+	pipe.ZRem(ctx, reviewLeaseIndexKey, members...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to release review leases: %w", err)
+	}
+	return nil
+}
+
+// activeLeases returns the currently leased artworks mapped to their reviewer ID
+// Expired entries are pruned from the lease index first
+func (s *ReviewLeaseService) activeLeases(ctx context.Context) (map[uint]string, error) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	if err := s.redis.ZRemRangeByScore(ctx, reviewLeaseIndexKey, "-inf", "("+now).Err(); err != nil {
+		return nil, fmt.Errorf("failed to prune review leases: %w", err)
+	}
+
+	members, err := s.redis.ZRange(ctx, reviewLeaseIndexKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list review leases: %w", err)
+	}
+
+	leases := make(map[uint]string, len(members))
+	if len(members) == 0 {
+		return leases, nil
+	}
+
+	keys := make([]string, 0, len(members))
+	for _, member := range members {
+		keys = append(keys, reviewLeaseKeyPrefix+member)
+	}
+
+	owners, err := s.redis.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read review leases: %w", err)
+	}
+
+	for i, member := range members {
+		owner, ok := owners[i].(string)
+		if !ok {
+			// Lease key expired before its index entry; it is no longer held
+			continue
+		}
+		artworkID, err := strconv.ParseUint(member, 10, 32)
+		if err != nil {
+			continue
+		}
+		leases[uint(artworkID)] = owner
+	}
+
+	return leases, nil
+}
+
+// leaseKey returns the Redis key of an artwork's review lease
+func leaseKey(artworkID uint) string {
+	return fmt.Sprintf("%s%d", reviewLeaseKeyPrefix, artworkID)
+}
@@ -0,0 +1,490 @@
+package service
+
+import (
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/repository"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScoringService handles business logic for rubric-based judging of activities
+type ScoringService struct {
+	scoringRepo     *repository.ScoringRepository
+	artworkRepo     *repository.ArtworkRepository
+	userRepo        *repository.UserRepository
+	activityService *ActivityService
+}
+
+// NewScoringService creates a new scoring service instance
+func NewScoringService(scoringRepo *repository.ScoringRepository, artworkRepo *repository.ArtworkRepository, userRepo *repository.UserRepository, activityService *ActivityService) *ScoringService {
+	return &ScoringService{
+		scoringRepo:     scoringRepo,
+		artworkRepo:     artworkRepo,
+		userRepo:        userRepo,
+		activityService: activityService,
+	}
+}
+
+// CriterionInput describes a rubric criterion submitted by an administrator
+type CriterionInput struct {
+	Name        string
+	Description string
+	Weight      float64
+	MinScore    float64
+	MaxScore    float64
+}
+
+// ScoreInput describes a judge's score for one rubric criterion
+type ScoreInput struct {
+	CriterionID uint
+	Score       float64
+}
+
+// JudgingArtwork is the view of an artwork shown to judges
+// It deliberately omits the author and the file name so that judging stays blind
+type JudgingArtwork struct {
+	ID           uint          `json:"id"`
+	ActivityID   uint          `json:"activity_id"`
+	Title        string        `json:"title"`
+	Description  string        `json:"description"`
+	Medium       string        `json:"medium"`
+	WidthCM      *float64      `json:"width_cm"`
+	HeightCM     *float64      `json:"height_cm"`
+	DepthCM      *float64      `json:"depth_cm"`
+	CreationYear *int          `json:"creation_year"`
+	Tags         []string      `json:"tags"`
+	PageCount    int           `json:"page_count"`
+	Pages        []JudgingPage `json:"pages,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+
+	// ImageURLs is filled by handlers with signed URLs of page 1
+	ImageURLs *models.ArtworkImageURLs `json:"image_urls,omitempty"`
+
+	// version identifies the file of page 1 for signing its image URLs
+	version string
+}
+
+// JudgingPage is the view of an additional page of a multi-page artwork shown to judges
+type JudgingPage struct {
+	Page int `json:"page"`
+
+	// ImageURLs is filled by handlers with signed URLs of the page
+	ImageURLs *models.ArtworkImageURLs `json:"image_urls,omitempty"`
+
+	version string
+}
+
+// newJudgingArtwork builds the judges' view of an artwork and of its loaded pages
+func newJudgingArtwork(artwork *models.Artwork) *JudgingArtwork {
+	view := &JudgingArtwork{
+		ID:           artwork.ID,
+		ActivityID:   artwork.ActivityID,
+		Title:        artwork.Title,
+		Description:  artwork.Description,
+		Medium:       artwork.Medium,
+		WidthCM:      artwork.WidthCM,
+		HeightCM:     artwork.HeightCM,
+		DepthCM:      artwork.DepthCM,
+		CreationYear: artwork.CreationYear,
+		Tags:         artwork.Tags,
+		PageCount:    artwork.PageCount,
+		CreatedAt:    artwork.CreatedAt,
+		version:      fileVersion(artwork.ContentHash, artwork.FilePath),
+	}
+	for _, page := range artwork.Pages {
+		view.Pages = append(view.Pages, JudgingPage{
+			Page:    page.Page,
+			version: fileVersion(page.ContentHash, page.FilePath),
+		})
+	}
+	return view
+}
+
+// JudgingEntry is an approved artwork together with the requesting judge's own scores
+type JudgingEntry struct {
+	Artwork *JudgingArtwork  `json:"artwork"`
+	Scores  map[uint]float64 `json:"scores"`
+}
+
+// CriterionResult is the average score an artwork received for one criterion
+type CriterionResult struct {
+	CriterionID  uint    `json:"criterion_id"`
+	Name         string  `json:"name"`
+	AverageScore float64 `json:"average_score"`
+	ScoreCount   int     `json:"score_count"`
+}
+
+// ScoringResult is an artwork's ranked position in an activity's scoring results
+// Artwork is the full *models.Artwork for administrators and a *JudgingArtwork for judges
+type ScoringResult struct {
+	Rank          int               `json:"rank"`
+	ArtworkID     uint              `json:"artwork_id"`
+	Artwork       interface{}       `json:"artwork"`
+	WeightedScore float64           `json:"weighted_score"`
+	JudgeCount    int               `json:"judge_count"`
+	Criteria      []CriterionResult `json:"criteria"`
+}
+
+// GetRubric retrieves the rubric criteria of an activity
+func (s *ScoringService) GetRubric(activityID uint) ([]models.RubricCriterion, error) {
+	if _, err := s.activityService.GetActivityByID(activityID); err != nil {
+		return nil, errors.New("活动不存在")
+	}
+
+	return s.scoringRepo.GetCriteria(activityID)
+}
+
+// SetRubric replaces the rubric criteria of an activity
+// The rubric cannot be changed once judges have started scoring against it
+func (s *ScoringService) SetRubric(activityID uint, inputs []CriterionInput) ([]models.RubricCriterion, error) {
+	if _, err := s.activityService.GetActivityByID(activityID); err != nil {
+		return nil, errors.New("活动不存在")
+	}
+
+	count, err := s.scoringRepo.CountScoresByActivity(activityID)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("已有评委评分，无法修改评分标准")
+	}
+
+	criteria := make([]models.RubricCriterion, 0, len(inputs))
+	for i, input := range inputs {
+		name := strings.TrimSpace(input.Name)
+		if name == "" {
+			return nil, fmt.Errorf("第%d项评分标准名称不能为空", i+1)
+		}
+		if input.Weight <= 0 {
+			return nil, fmt.Errorf("评分标准\"%s\"的权重必须大于0", name)
+		}
+		if input.MaxScore <= input.MinScore {
+			return nil, fmt.Errorf("评分标准\"%s\"的最高分必须大于最低分", name)
+		}
+
+		criteria = append(criteria, models.RubricCriterion{
+			ActivityID:  activityID,
+			Name:        name,
+			Description: input.Description,
+			Weight:      input.Weight,
+			MinScore:    input.MinScore,
+			MaxScore:    input.MaxScore,
+			SortOrder:   i,
+		})
+	}
+
+	if err := s.scoringRepo.ReplaceCriteria(activityID, criteria); err != nil {
+		return nil, err
+	}
+
+	return criteria, nil
+}
+
+// GetJudges retrieves the judges assigned to an activity
+func (s *ScoringService) GetJudges(activityID uint) ([]models.ActivityJudge, error) {
+	if _, err := s.activityService.GetActivityByID(activityID); err != nil {
+		return nil, errors.New("活动不存在")
+	}
+
+	return s.scoringRepo.GetJudges(activityID)
+}
+
+// SetJudges replaces the judges assigned to an activity
+func (s *ScoringService) SetJudges(activityID uint, userIDs []uint) error {
+	if _, err := s.activityService.GetActivityByID(activityID); err != nil {
+		return errors.New("活动不存在")
+	}
+
+	// Deduplicate and verify that every judge is an existing user
+	seen := make(map[uint]bool, len(userIDs))
+	judgeIDs := make([]uint, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		if _, err := s.userRepo.GetByID(userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("用户不存在: %d", userID)
+			}
+			return err
+		}
+		judgeIDs = append(judgeIDs, userID)
+	}
+
+	return s.scoringRepo.ReplaceJudges(activityID, judgeIDs)
+}
+
+// SetScoringClosed opens or closes scoring for an activity
+// Closing scoring freezes the scores and makes the results visible to judges
+func (s *ScoringService) SetScoringClosed(activityID uint, closed bool) error {
+	if err := s.activityService.SetScoringClosed(activityID, closed); err != nil {
+		return errors.New("活动不存在")
+	}
+	return nil
+}
+
+// GetJudgedActivities retrieves the activities a user is assigned to judge
+func (s *ScoringService) GetJudgedActivities(judgeID uint) ([]models.Activity, error) {
+	activityIDs, err := s.scoringRepo.GetJudgedActivityIDs(judgeID)
+	if err != nil {
+		return nil, err
+	}
+
+	activities := make([]models.Activity, 0, len(activityIDs))
+	for _, activityID := range activityIDs {
+		activity, err := s.activityService.GetActivityByID(activityID)
+		if err != nil {
+			// Skip activities that have been deleted
+			continue
+		}
+		activities = append(activities, *activity)
+	}
+
+	return activities, nil
+}
+
+// GetJudgingSheet retrieves the rubric and approved artworks of an activity along with
+// the requesting judge's own scores; other judges' scores are never included
+func (s *ScoringService) GetJudgingSheet(activityID, judgeID uint) ([]models.RubricCriterion, []JudgingEntry, error) {
+	if err := s.requireJudge(activityID, judgeID); err != nil {
+		return nil, nil, err
+	}
+
+	criteria, err := s.scoringRepo.GetCriteria(activityID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	artworks, err := s.artworkRepo.GetByActivityIDAndStatus(activityID, models.StatusApproved)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scores, err := s.scoringRepo.GetScoresByJudge(activityID, judgeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scoresByArtwork := make(map[uint]map[uint]float64)
+	for _, score := range scores {
+		if scoresByArtwork[score.ArtworkID] == nil {
+			scoresByArtwork[score.ArtworkID] = make(map[uint]float64)
+		}
+		scoresByArtwork[score.ArtworkID][score.CriterionID] = score.Score
+	}
+
+	entries := make([]JudgingEntry, 0, len(artworks))
+	for i := range artworks {
+		artworkScores := scoresByArtwork[artworks[i].ID]
+		if artworkScores == nil {
+			artworkScores = map[uint]float64{}
+		}
+		entries = append(entries, JudgingEntry{Artwork: newJudgingArtwork(&artworks[i]), Scores: artworkScores})
+	}
+
+	return criteria, entries, nil
+}
+
+// SubmitScores records a judge's scores for an approved artwork
+func (s *ScoringService) SubmitScores(judgeID, artworkID uint, inputs []ScoreInput) error {
+	if len(inputs) == 0 {
+		return errors.New("评分不能为空")
+	}
+
+	artwork, err := s.artworkRepo.GetByID(artworkID)
+	if err != nil {
+		return errors.New("作品不存在")
+	}
+	if artwork.ReviewStatus != models.StatusApproved {
+		return errors.New("只能为已审核通过的作品评分")
+	}
+
+	if err := s.requireJudge(artwork.ActivityID, judgeID); err != nil {
+		return err
+	}
+
+	activity, err := s.activityService.GetActivityByID(artwork.ActivityID)
+	if err != nil {
+		return errors.New("活动不存在")
+	}
+	if activity.ScoringClosed {
+		return errors.New("评分已结束，无法修改评分")
+	}
+
+	criteria, err := s.scoringRepo.GetCriteria(artwork.ActivityID)
+	if err != nil {
+		return err
+	}
+	criteriaByID := make(map[uint]models.RubricCriterion, len(criteria))
+	for _, criterion := range criteria {
+		criteriaByID[criterion.ID] = criterion
+	}
+
+	scores := make([]models.ArtworkScore, 0, len(inputs))
+	for _, input := range inputs {
+		criterion, ok := criteriaByID[input.CriterionID]
+		if !ok {
+			return fmt.Errorf("无效的评分标准: %d", input.CriterionID)
+		}
+		if input.Score < criterion.MinScore || input.Score > criterion.MaxScore {
+			return fmt.Errorf("评分标准\"%s\"的分数必须在%g到%g之间", criterion.Name, criterion.MinScore, criterion.MaxScore)
+		}
+
+		scores = append(scores, models.ArtworkScore{
+			ArtworkID:   artworkID,
+			CriterionID: input.CriterionID,
+			JudgeID:     judgeID,
+			Score:       input.Score,
+		})
+	}
+
+	return s.scoringRepo.UpsertScores(scores)
+}
+
+// GetResults computes the ranked scoring results of an activity
+// Each criterion's scores are averaged across judges and normalized to 0-100, then
+// combined by weight; unscored criteria count as their minimum score.
+// Judges may only see the results once scoring is closed; administrators who are
+// not judging the activity may view them at any time.
+func (s *ScoringService) GetResults(activityID, requesterID uint, requesterRole string) ([]ScoringResult, error) {
+	activity, err := s.activityService.GetActivityByID(activityID)
+	if err != nil {
+		return nil, errors.New("活动不存在")
+	}
+
+	isJudge, err := s.scoringRepo.IsJudge(activityID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if !isJudge && requesterRole != "admin" {
+		return nil, errors.New("权限不足，仅评委或管理员可查看评分结果")
+	}
+	if isJudge && !activity.ScoringClosed {
+		return nil, errors.New("权限不足，评分结束前无法查看评分结果")
+	}
+
+	criteria, err := s.scoringRepo.GetCriteria(activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	artworks, err := s.artworkRepo.GetByActivityIDAndStatus(activityID, models.StatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := s.scoringRepo.GetScoresByActivity(activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Judges keep the blind view of the artworks even after scoring closes
+	view := func(artwork *models.Artwork) interface{} { return artwork }
+	if requesterRole != "admin" {
+		view = func(artwork *models.Artwork) interface{} { return newJudgingArtwork(artwork) }
+	}
+
+	return rankArtworks(criteria, artworks, scores, view), nil
+}
+
+// requireJudge verifies that a user is assigned as a judge of an activity
+func (s *ScoringService) requireJudge(activityID, judgeID uint) error {
+	isJudge, err := s.scoringRepo.IsJudge(activityID, judgeID)
+	if err != nil {
+		return err
+	}
+	if !isJudge {
+		return errors.New("权限不足，您不是该活动的评委")
+	}
+	return nil
+}
+
+// rankArtworks computes weighted scores for the artworks and orders them by rank
+// view builds the artwork shown in each result
+func rankArtworks(criteria []models.RubricCriterion, artworks []models.Artwork, scores []models.ArtworkScore, view func(artwork *models.Artwork) interface{}) []ScoringResult {
+	type scoreKey struct {
+		artworkID   uint
+		criterionID uint
+	}
+
+	sums := make(map[scoreKey]float64)
+	counts := make(map[scoreKey]int)
+	judges := make(map[uint]map[uint]bool)
+	for _, score := range scores {
+		key := scoreKey{score.ArtworkID, score.CriterionID}
+		sums[key] += score.Score
+		counts[key]++
+		if judges[score.ArtworkID] == nil {
+			judges[score.ArtworkID] = make(map[uint]bool)
+		}
+		judges[score.ArtworkID][score.JudgeID] = true
+	}
+
+	totalWeight := 0.0
+	for _, criterion := range criteria {
+		totalWeight += criterion.Weight
+	}
+
+	results := make([]ScoringResult, 0, len(artworks))
+	for i := range artworks {
+		artwork := &artworks[i]
+		result := ScoringResult{
+			ArtworkID:  artwork.ID,
+			Artwork:    view(artwork),
+			JudgeCount: len(judges[artwork.ID]),
+			Criteria:   make([]CriterionResult, 0, len(criteria)),
+		}
+
+		weighted := 0.0
+		for _, criterion := range criteria {
+			key := scoreKey{artwork.ID, criterion.ID}
+			average := criterion.MinScore
+			if counts[key] > 0 {
+				average = sums[key] / float64(counts[key])
+			}
+
+			normalized := (average - criterion.MinScore) / (criterion.MaxScore - criterion.MinScore) * 100
+			weighted += criterion.Weight * normalized
+
+			result.Criteria = append(result.Criteria, CriterionResult{
+				CriterionID:  criterion.ID,
+				Name:         criterion.Name,
+				AverageScore: roundScore(average),
+				ScoreCount:   counts[key],
+			})
+		}
+
+		if totalWeight > 0 {
+			result.WeightedScore = roundScore(weighted / totalWeight)
+		}
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].WeightedScore > results[j].WeightedScore
+	})
+
+	// Equal scores share the same rank (1, 2, 2, 4)
+	for i := range results {
+		if i > 0 && results[i].WeightedScore == results[i-1].WeightedScore {
+			results[i].Rank = results[i-1].Rank
+		} else {
+			results[i].Rank = i + 1
+		}
+	}
+
+	return results
+}
+
+// roundScore rounds a score to two decimal places
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
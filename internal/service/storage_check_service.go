@@ -0,0 +1,297 @@
+package service
+
+import (
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/repository"
+	"art-collection-system/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// QuarantinePrefix is the key prefix orphaned files are moved under when quarantined
+const QuarantinePrefix = "quarantine/"
+
+// Storage check actions
+const (
+	StorageActionReport     = "report"
+	StorageActionQuarantine = "quarantine"
+	StorageActionDelete     = "delete"
+)
+
+// StorageCheckService compares the stored files with the database records referencing them
+type StorageCheckService struct {
+	store       storage.Storage
+	artworkRepo *repository.ArtworkRepository
+	blobRepo    *repository.BlobRepository
+}
+
+// NewStorageCheckService creates a new storage check service instance
+func NewStorageCheckService(store storage.Storage, artworkRepo *repository.ArtworkRepository, blobRepo *repository.BlobRepository) *StorageCheckService {
+	return &StorageCheckService{
+		store:       store,
+		artworkRepo: artworkRepo,
+		blobRepo:    blobRepo,
+	}
+}
+
+// OrphanedFile is a stored file no record references
+type OrphanedFile struct {
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	// Eligible reports whether the file is older than the grace period and may be cleaned up
+	Eligible bool `json:"eligible"`
+}
+
+// UnreferencedBlob is a blob row no artwork, page or revision points at
+type UnreferencedBlob struct {
+	ID        uint      `json:"id"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Eligible  bool      `json:"eligible"`
+}
+
+// MissingFile is a record whose file is not in storage
+type MissingFile struct {
+	// Kind is "artwork", "page", "revision" or "blob"
+	Kind      string `json:"kind"`
+	ID        uint   `json:"id"`
+	ArtworkID uint   `json:"artwork_id,omitempty"`
+	Path      string `json:"path"`
+}
+
+// BlobRefMismatch is a blob whose stored reference count differs from the actual references
+type BlobRefMismatch struct {
+	ID         uint `json:"id"`
+	RefCount   int  `json:"ref_count"`
+	References int  `json:"references"`
+}
+
+// StorageReport is the result of a storage consistency check
+type StorageReport struct {
+	Action            string             `json:"action"`
+	GracePeriod       string             `json:"grace_period"`
+	ObjectCount       int                `json:"object_count"`
+	TotalBytes        int64              `json:"total_bytes"`
+	OrphanedFiles     []OrphanedFile     `json:"orphaned_files"`
+	UnreferencedBlobs []UnreferencedBlob `json:"unreferenced_blobs"`
+	// ReclaimableBytes is the size of all orphans older than the grace period
+	ReclaimableBytes  int64             `json:"reclaimable_bytes"`
+	MissingFiles      []MissingFile     `json:"missing_files"`
+	BlobRefMismatches []BlobRefMismatch `json:"blob_ref_mismatches"`
+	// Processed and Failed count the orphans quarantined or deleted
+	Processed int `json:"processed"`
+	Failed    int `json:"failed"`
+}
+
+// Check lists orphaned files and records pointing at missing files
+// With the quarantine or delete action, orphans older than gracePeriod are moved under
+// QuarantinePrefix or deleted; with the report action nothing is changed. Files younger
+// than the grace period are only reported, since an upload stores its file before the
+// record referencing it is created.
+func (s *StorageCheckService) Check(ctx context.Context, gracePeriod time.Duration, action string) (*StorageReport, error) {
+	switch action {
+	case StorageActionReport, StorageActionQuarantine, StorageActionDelete:
+	default:
+		return nil, fmt.Errorf("不支持的操作: %s", action)
+	}
+	if gracePeriod < 0 {
+		return nil, errors.New("宽限期不能为负数")
+	}
+
+	report := &StorageReport{
+		Action:            action,
+		GracePeriod:       gracePeriod.String(),
+		OrphanedFiles:     []OrphanedFile{},
+		UnreferencedBlobs: []UnreferencedBlob{},
+		MissingFiles:      []MissingFile{},
+		BlobRefMismatches: []BlobRefMismatch{},
+	}
+	cutoff := time.Now().Add(-gracePeriod)
+
+	// Load the records before listing, so files stored meanwhile are covered by the grace period
+	references, err := s.artworkRepo.GetFileReferences()
+	if err != nil {
+		return nil, fmt.Errorf("获取作品文件失败: %w", err)
+	}
+	blobs, err := s.blobRepo.GetUsage()
+	if err != nil {
+		return nil, fmt.Errorf("获取文件数据失败: %w", err)
+	}
+
+	objects := make(map[string]storage.ObjectInfo)
+	err = s.store.List(ctx, "", func(info storage.ObjectInfo) error {
+		// Quarantined files and parts of unfinished resumable uploads are not referenced by design
+		if strings.HasPrefix(info.Key, QuarantinePrefix) || strings.HasPrefix(info.Key, ResumableUploadPrefix) {
+			return nil
+		}
+		objects[info.Key] = info
+		report.ObjectCount++
+		report.TotalBytes += info.Size
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("列出存储文件失败: %w", err)
+	}
+
+	referenced := make(map[string]bool)
+	reference := func(filePath string) {
+		key := storageKey(filePath)
+		referenced[key] = true
+		for _, renditionPath := range RenditionPaths(key) {
+			referenced[renditionPath] = true
+		}
+	}
+	// exists reports whether a referenced file is stored, falling back to Stat
+	// for paths outside the listing such as absolute legacy paths
+	exists := func(filePath string) bool {
+		if _, ok := objects[storageKey(filePath)]; ok {
+			return true
+		}
+		_, err := s.store.Stat(ctx, filePath)
+		return err == nil
+	}
+
+	for _, ref := range references {
+		reference(ref.FilePath)
+		if !exists(ref.FilePath) {
+			report.MissingFiles = append(report.MissingFiles, MissingFile{
+				Kind:      ref.Kind,
+				ID:        ref.ID,
+				ArtworkID: ref.ArtworkID,
+				Path:      ref.FilePath,
+			})
+		}
+	}
+
+	var unreferenced []repository.BlobUsage
+	for _, blob := range blobs {
+		reference(blob.Path)
+		if !exists(blob.Path) {
+			report.MissingFiles = append(report.MissingFiles, MissingFile{Kind: "blob", ID: blob.ID, Path: blob.Path})
+		}
+		if blob.RefCount != blob.References {
+			report.BlobRefMismatches = append(report.BlobRefMismatches, BlobRefMismatch{
+				ID:         blob.ID,
+				RefCount:   blob.RefCount,
+				References: blob.References,
+			})
+		}
+		if blob.References == 0 {
+			unreferenced = append(unreferenced, blob)
+		}
+	}
+
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var eligibleFiles []OrphanedFile
+	for _, key := range keys {
+		info := objects[key]
+		if referenced[key] {
+			continue
+		}
+		orphan := OrphanedFile{Key: key, Size: info.Size, ModTime: info.ModTime, Eligible: info.ModTime.Before(cutoff)}
+		report.OrphanedFiles = append(report.OrphanedFiles, orphan)
+		if orphan.Eligible {
+			report.ReclaimableBytes += orphan.Size
+			eligibleFiles = append(eligibleFiles, orphan)
+		}
+	}
+
+	var eligibleBlobs []UnreferencedBlob
+	for _, blob := range unreferenced {
+		item := UnreferencedBlob{
+			ID:        blob.ID,
+			Path:      blob.Path,
+			Size:      blob.Size,
+			UpdatedAt: blob.UpdatedAt,
+			Eligible:  blob.UpdatedAt.Before(cutoff),
+		}
+		report.UnreferencedBlobs = append(report.UnreferencedBlobs, item)
+		if item.Eligible {
+			report.ReclaimableBytes += s.storedSize(objects, blob.Path)
+			eligibleBlobs = append(eligibleBlobs, item)
+		}
+	}
+
+	if action == StorageActionReport {
+		return report, nil
+	}
+
+	for _, orphan := range eligibleFiles {
+		if err := s.removeObject(ctx, orphan.Key, action); err != nil {
+			report.Failed++
+			continue
+		}
+		report.Processed++
+	}
+
+	for _, item := range eligibleBlobs {
+		// Re-check under the row lock in case an upload took a reference since the listing
+		removed, err := s.blobRepo.DeleteUnreferenced(item.ID, cutoff, func(blob *models.Blob) error {
+			for _, renditionPath := range RenditionPaths(blob.Path) {
+				if err := s.removeObject(ctx, renditionPath, action); err != nil && !errors.Is(err, storage.ErrNotFound) {
+					return err
+				}
+			}
+			if err := s.removeObject(ctx, blob.Path, action); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			report.Failed++
+			continue
+		}
+		if removed {
+			report.Processed++
+		}
+	}
+
+	return report, nil
+}
+
+// storedSize sums the listed sizes of a file and its renditions
+func (s *StorageCheckService) storedSize(objects map[string]storage.ObjectInfo, filePath string) int64 {
+	key := storageKey(filePath)
+	size := objects[key].Size
+	for _, renditionPath := range RenditionPaths(key) {
+		size += objects[renditionPath].Size
+	}
+	return size
+}
+
+// removeObject quarantines or deletes a single stored object
+func (s *StorageCheckService) removeObject(ctx context.Context, key, action string) error {
+	if action == StorageActionQuarantine {
+		reader, info, err := s.store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		err = s.store.Put(ctx, QuarantinePrefix+key, reader, info.Size, storage.PutOptions{
+			ContentType: getContentType(key),
+			SHA256:      info.SHA256,
+		})
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return s.store.Delete(ctx, key)
+}
+
+// storageKey normalizes a stored file path to the form storage listings use
+func storageKey(filePath string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(filePath)), "./")
+}
@@ -100,7 +100,7 @@ func (s *UserService) ChangePassword(userID uint, oldPassword, newPassword strin
 }
 
 // GetUserArtworks retrieves all artworks for a specific user with permission check
-// Only the user themselves or an administrator can access
+// Only the user themselves or an administrator can access; drafts are only listed for the user themselves
 func (s *UserService) GetUserArtworks(userID uint, requesterID uint, requesterRole string) ([]models.Artwork, error) {
 	// Permission check: only the user themselves or admin can access
 	if requesterRole != "admin" && requesterID != userID {
@@ -122,5 +122,15 @@ func (s *UserService) GetUserArtworks(userID uint, requesterID uint, requesterRo
 		return nil, errors.New("获取用户作品失败")
 	}
 
+	if requesterID != userID {
+		submitted := artworks[:0]
+		for _, artwork := range artworks {
+			if artwork.ReviewStatus != models.StatusDraft {
+				submitted = append(submitted, artwork)
+			}
+		}
+		artworks = submitted
+	}
+
 	return artworks, nil
 }
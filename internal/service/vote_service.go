@@ -0,0 +1,450 @@
+package service
+
+import (
+	"art-collection-system/internal/models"
+	"art-collection-system/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// voteCountsKeyPrefix is the Redis key prefix of a hash of vote counts by artwork ID
+	// Key format: vote:counts:{activityID}; the "_" field only marks the hash as loaded
+	voteCountsKeyPrefix = "vote:counts:"
+
+	// voteUserKeyPrefix is the Redis key prefix of the set of artwork IDs a user voted for
+	// Key format: vote:user:{activityID}:{userID}; the "0" member only marks the set as loaded
+	voteUserKeyPrefix = "vote:user:"
+
+	// voteDirtyKey is a set of IDs of activities whose votes changed since their counts were last synced
+	voteDirtyKey = "vote:dirty"
+
+	// voteKeyTTL is how long the counters of an activity nobody votes in are kept in Redis;
+	// they are loaded again from the database when needed
+	voteKeyTTL = 7 * 24 * time.Hour
+
+	// voteResetScanCount is the number of keys examined per SCAN call when resetting an activity's counters
+	voteResetScanCount = 1000
+)
+
+// Results of the vote scripts besides a vote count
+const (
+	voteScriptAlreadyVoted = -1
+	voteScriptLimitReached = -2
+	voteScriptNotLoaded    = -3
+)
+
+// castVoteScript adds a user's vote for an artwork to the counters unless the user already
+// voted for it or has used up the activity's limit (ARGV[2], 0 for none), and returns the
+// artwork's new vote count
+var castVoteScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 or redis.call("EXISTS", KEYS[2]) == 0 then
+	return -3
+end
+if redis.call("SISMEMBER", KEYS[1], ARGV[1]) == 1 then
+	return -1
+end
+local limit = tonumber(ARGV[2])
+if limit > 0 and redis.call("SCARD", KEYS[1]) - 1 >= limit then
+	return -2
+end
+redis.call("SADD", KEYS[1], ARGV[1])
+redis.call("EXPIRE", KEYS[1], ARGV[3])
+redis.call("EXPIRE", KEYS[2], ARGV[3])
+return redis.call("HINCRBY", KEYS[2], ARGV[1], 1)
+`)
+
+// retractVoteScript removes a user's vote for an artwork from the counters and returns the
+// artwork's new vote count
+var retractVoteScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 or redis.call("EXISTS", KEYS[2]) == 0 then
+	return -3
+end
+if redis.call("SREM", KEYS[1], ARGV[1]) == 0 then
+	return -1
+end
+local count = redis.call("HINCRBY", KEYS[2], ARGV[1], -1)
+if count < 0 then
+	redis.call("HSET", KEYS[2], ARGV[1], 0)
+	count = 0
+end
+return count
+`)
+
+// loadUserVotesScript fills a user's vote set unless it is already loaded
+var loadUserVotesScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+for i = 2, #ARGV do
+	redis.call("SADD", KEYS[1], ARGV[i])
+end
+redis.call("EXPIRE", KEYS[1], ARGV[1])
+return 1
+`)
+
+// loadVoteCountsScript fills an activity's vote count hash unless it is already loaded
+var loadVoteCountsScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+for i = 2, #ARGV, 2 do
+	redis.call("HSET", KEYS[1], ARGV[i], ARGV[i + 1])
+end
+redis.call("EXPIRE", KEYS[1], ARGV[1])
+return 1
+`)
+
+// VoteStatus describes a user's votes in an activity
+type VoteStatus struct {
+	VotingOpen     bool       `json:"voting_open"`
+	VotingOpensAt  *time.Time `json:"voting_opens_at"`
+	VotingClosesAt *time.Time `json:"voting_closes_at"`
+	MaxVotes       int        `json:"max_votes"`       // 0 allows a vote for every artwork
+	RemainingVotes *int       `json:"remaining_votes"` // nil when there is no limit
+	ArtworkIDs     []uint     `json:"artwork_ids"`
+}
+
+// VoteService handles public voting for approved artworks. Votes are stored in the
+// database, while Redis holds live counters that also enforce the per-user limits
+// atomically. Vote counts are synced into artworks.vote_count periodically, and the
+// counters of the synced activities are reloaded from the database at the same time.
+// Votes for artworks in the trash are left out of the counters.
+type VoteService struct {
+	redis           *redis.Client
+	voteRepo        *repository.VoteRepository
+	artworkRepo     *repository.ArtworkRepository
+	activityService *ActivityService
+}
+
+// NewVoteService creates a new vote service instance
+func NewVoteService(redisClient *redis.Client, voteRepo *repository.VoteRepository, artworkRepo *repository.ArtworkRepository, activityService *ActivityService) *VoteService {
+	return &VoteService{
+		redis:           redisClient,
+		voteRepo:        voteRepo,
+		artworkRepo:     artworkRepo,
+		activityService: activityService,
+	}
+}
+
+// voteCountsKey returns the Redis key of an activity's vote counts
+func voteCountsKey(activityID uint) string {
+	return fmt.Sprintf("%s%d", voteCountsKeyPrefix, activityID)
+}
+
+// voteUserKey returns the Redis key of the set of artworks a user voted for in an activity
+func voteUserKey(activityID, userID uint) string {
+	return fmt.Sprintf("%s%d:%d", voteUserKeyPrefix, activityID, userID)
+}
+
+// votingActivity retrieves the activity of an artwork and verifies that voting is open
+func (s *VoteService) votingActivity(artwork *models.Artwork) (*models.Activity, error) {
+	activity, err := s.activityService.GetActivityByID(artwork.ActivityID)
+	if err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if !activity.IsVotingOpen(time.Now()) {
+		return nil, errors.New("该活动当前不在投票时间内")
+	}
+	return activity, nil
+}
+
+// Vote records a user's vote for an approved artwork and returns its new vote count
+func (s *VoteService) Vote(userID, artworkID uint) (int64, error) {
+	artwork, err := s.artworkRepo.GetByID(artworkID)
+	if err != nil || artwork.ReviewStatus != models.StatusApproved {
+		return 0, errors.New("作品不存在")
+	}
+	if artwork.UserID == userID {
+		return 0, errors.New("不能为自己的作品投票")
+	}
+	activity, err := s.votingActivity(artwork)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	count, err := s.runVoteScript(ctx, castVoteScript, activity.ID, userID, artworkID, activity.MaxVotesPerUser)
+	if err != nil {
+		return 0, err
+	}
+	switch count {
+	case voteScriptAlreadyVoted:
+		return 0, errors.New("已经为该作品投过票")
+	case voteScriptLimitReached:
+		return 0, fmt.Errorf("每人在该活动中最多投%d票", activity.MaxVotesPerUser)
+	}
+
+	recorded, err := s.voteRepo.Create(&models.Vote{ActivityID: activity.ID, ArtworkID: artworkID, UserID: userID})
+	if err != nil || !recorded {
+		// Take the vote back out of the counters; if that fails too, mark the activity
+		// so the next sync reloads its counters from the database
+		if _, retractErr := s.runVoteScript(ctx, retractVoteScript, activity.ID, userID, artworkID, 0); retractErr != nil {
+			s.markChanged(ctx, activity.ID)
+		}
+		if err != nil {
+			return 0, err
+		}
+		return 0, errors.New("已经为该作品投过票")
+	}
+
+	s.markChanged(ctx, activity.ID)
+	return count, nil
+}
+
+// Unvote withdraws a user's vote for an artwork while voting is open and returns its new vote count
+func (s *VoteService) Unvote(userID, artworkID uint) (int64, error) {
+	// The vote can be withdrawn even if the artwork has since been moved to the trash
+	artwork, err := s.artworkRepo.GetByIDIncludingDeleted(artworkID)
+	if err != nil {
+		return 0, errors.New("作品不存在")
+	}
+	activity, err := s.votingActivity(artwork)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	count, err := s.runVoteScript(ctx, retractVoteScript, activity.ID, userID, artworkID, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := s.voteRepo.Delete(artworkID, userID)
+	if err != nil {
+		if count != voteScriptAlreadyVoted {
+			// Put the vote back into the counters, ignoring the limit it already counted against;
+			// if that fails too, the next sync reloads the counters from the database
+			if _, castErr := s.runVoteScript(ctx, castVoteScript, activity.ID, userID, artworkID, 0); castErr != nil {
+				s.markChanged(ctx, activity.ID)
+			}
+		}
+		return 0, err
+	}
+	if !deleted {
+		return 0, errors.New("尚未为该作品投票")
+	}
+
+	s.markChanged(ctx, activity.ID)
+	if count == voteScriptAlreadyVoted {
+		// The counters had missed the vote; report the stored count until the next sync
+		return s.countVotes(ctx, activity.ID, artworkID)
+	}
+	return count, nil
+}
+
+// runVoteScript runs a vote script for a user and artwork, loading the counters first
+// when they are not in Redis
+func (s *VoteService) runVoteScript(ctx context.Context, script *redis.Script, activityID, userID, artworkID uint, limit int) (int64, error) {
+	keys := []string{voteUserKey(activityID, userID), voteCountsKey(activityID)}
+	ttl := int64(voteKeyTTL.Seconds())
+
+	for attempt := 0; ; attempt++ {
+		result, err := script.Run(ctx, s.redis, keys, artworkID, limit, ttl).Int64()
+		if err != nil {
+			return 0, fmt.Errorf("failed to update vote counters: %w", err)
+		}
+		if result != voteScriptNotLoaded || attempt > 0 {
+			if result == voteScriptNotLoaded {
+				return 0, errors.New("投票计数暂不可用，请稍后重试")
+			}
+			return result, nil
+		}
+
+		if err := s.loadUserVotes(ctx, activityID, userID); err != nil {
+			return 0, err
+		}
+		if err := s.loadVoteCounts(ctx, activityID); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// loadUserVotes copies a user's votes in an activity from the database into Redis
+func (s *VoteService) loadUserVotes(ctx context.Context, activityID, userID uint) error {
+	artworkIDs, err := s.voteRepo.GetArtworkIDsByUser(activityID, userID)
+	if err != nil {
+		return err
+	}
+
+	args := make([]interface{}, 0, len(artworkIDs)+2)
+	args = append(args, int64(voteKeyTTL.Seconds()), 0)
+	for _, id := range artworkIDs {
+		args = append(args, id)
+	}
+	if err := loadUserVotesScript.Run(ctx, s.redis, []string{voteUserKey(activityID, userID)}, args...).Err(); err != nil {
+		return fmt.Errorf("failed to load votes: %w", err)
+	}
+	return nil
+}
+
+// loadVoteCounts copies the vote counts of an activity's artworks from the database into Redis
+func (s *VoteService) loadVoteCounts(ctx context.Context, activityID uint) error {
+	counts, err := s.voteRepo.CountByArtwork(activityID)
+	if err != nil {
+		return err
+	}
+
+	args := make([]interface{}, 0, 2*len(counts)+3)
+	args = append(args, int64(voteKeyTTL.Seconds()), "_", 0)
+	for id, count := range counts {
+		args = append(args, id, count)
+	}
+	if err := loadVoteCountsScript.Run(ctx, s.redis, []string{voteCountsKey(activityID)}, args...).Err(); err != nil {
+		return fmt.Errorf("failed to load vote counts: %w", err)
+	}
+	return nil
+}
+
+// countVotes reads one artwork's vote count from the database
+func (s *VoteService) countVotes(ctx context.Context, activityID, artworkID uint) (int64, error) {
+	counts, err := s.voteRepo.CountByArtwork(activityID)
+	if err != nil {
+		return 0, err
+	}
+	return counts[artworkID], nil
+}
+
+// markChanged records that an activity's votes changed, so the next sync stores its counts
+// and reloads its counters
+// A failure only delays the stored counts until the activity's votes change again
+func (s *VoteService) markChanged(ctx context.Context, activityID uint) {
+	s.redis.SAdd(ctx, voteDirtyKey, activityID)
+}
+
+// GetCounts returns the vote counts of artworks of an activity by artwork ID
+// While voting is open they come from the live counters; otherwise, or if Redis is
+// unavailable, the counts last synced into the artworks are used
+func (s *VoteService) GetCounts(activity *models.Activity, artworks []models.Artwork) map[uint]int64 {
+	counts := make(map[uint]int64, len(artworks))
+	for _, artwork := range artworks {
+		counts[artwork.ID] = artwork.VoteCount
+	}
+	if len(artworks) == 0 || !activity.IsVotingOpen(time.Now()) {
+		return counts
+	}
+
+	ctx := context.Background()
+	fields := make([]string, 0, len(artworks)+1)
+	fields = append(fields, "_")
+	for _, artwork := range artworks {
+		fields = append(fields, strconv.FormatUint(uint64(artwork.ID), 10))
+	}
+
+	values, err := s.redis.HMGet(ctx, voteCountsKey(activity.ID), fields...).Result()
+	if err == nil && values[0] == nil {
+		// The counters are not loaded yet
+		if err = s.loadVoteCounts(ctx, activity.ID); err == nil {
+			values, err = s.redis.HMGet(ctx, voteCountsKey(activity.ID), fields...).Result()
+		}
+	}
+	if err != nil {
+		return counts
+	}
+
+	for i, artwork := range artworks {
+		value, _ := values[i+1].(string)
+		count, _ := strconv.ParseInt(value, 10, 64)
+		counts[artwork.ID] = count
+	}
+	return counts
+}
+
+// GetUserVotes describes the votes a user cast in an activity and how many remain
+func (s *VoteService) GetUserVotes(userID, activityID uint) (*VoteStatus, error) {
+	activity, err := s.activityService.GetActivityByID(activityID)
+	if err != nil {
+		return nil, errors.New("活动不存在")
+	}
+
+	artworkIDs, err := s.voteRepo.GetArtworkIDsByUser(activityID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &VoteStatus{
+		VotingOpen:     activity.IsVotingOpen(time.Now()),
+		VotingOpensAt:  activity.VotingOpensAt,
+		VotingClosesAt: activity.VotingClosesAt,
+		MaxVotes:       activity.MaxVotesPerUser,
+		ArtworkIDs:     artworkIDs,
+	}
+	if activity.MaxVotesPerUser > 0 {
+		remaining := max(activity.MaxVotesPerUser-len(artworkIDs), 0)
+		status.RemainingVotes = &remaining
+	}
+	return status, nil
+}
+
+// resetCounters deletes an activity's live counters so they are loaded again from the database when next used
+// A vote being cast meanwhile may be missing from the reloaded counters; it marks the activity
+// as changed once it is stored, so the next sync reloads the counters again
+func (s *VoteService) resetCounters(ctx context.Context, activityID uint) error {
+	keys := []string{voteCountsKey(activityID)}
+	iter := s.redis.Scan(ctx, 0, fmt.Sprintf("%s%d:*", voteUserKeyPrefix, activityID), voteResetScanCount).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to find vote counters: %w", err)
+	}
+
+	if err := s.redis.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to reset vote counters: %w", err)
+	}
+	return nil
+}
+
+// ReloadArtworkVotes reloads the live counters of an artwork's activity after the artwork was
+// moved to the trash or restored from it, so its votes stop or start counting toward the voters'
+// limits. Artworks purged from the trash are already left out of the counters.
+func (s *VoteService) ReloadArtworkVotes(artwork *models.Artwork) error {
+	hasVotes, err := s.voteRepo.HasVotes(artwork.ID)
+	if err != nil || !hasVotes {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := s.resetCounters(ctx, artwork.ActivityID); err != nil {
+		// Let the next sync reload the counters instead
+		s.markChanged(ctx, artwork.ActivityID)
+		return err
+	}
+	return nil
+}
+
+// SyncVoteCounts stores the vote counts of the activities whose votes changed since the last
+// sync in artworks.vote_count and reloads their live counters from the database, correcting
+// counters that missed a change; it returns how many activities were synced
+func (s *VoteService) SyncVoteCounts(ctx context.Context) (int, error) {
+	synced := 0
+	for {
+		member, err := s.redis.SPop(ctx, voteDirtyKey).Result()
+		if err == redis.Nil {
+			return synced, nil
+		}
+		if err != nil {
+			return synced, fmt.Errorf("failed to read changed activities: %w", err)
+		}
+
+		activityID, err := strconv.ParseUint(member, 10, 32)
+		if err != nil {
+			continue
+		}
+		if err := s.voteRepo.SyncArtworkCounts(uint(activityID)); err != nil {
+			// Keep the activity marked so the next sync retries it
+			s.redis.SAdd(ctx, voteDirtyKey, member)
+			return synced, err
+		}
+		if err := s.resetCounters(ctx, uint(activityID)); err != nil {
+			s.redis.SAdd(ctx, voteDirtyKey, member)
+			return synced, err
+		}
+		synced++
+	}
+}
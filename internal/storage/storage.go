@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Driver names accepted in the storage configuration
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	// Key is the object key, a slash-separated path relative to the storage root
+	Key     string
+	Size    int64
+	ModTime time.Time
+	// SHA256 is the hex SHA-256 of the content when the backend recorded it at upload, otherwise empty
+	SHA256 string
+}
+
+// PutOptions holds optional attributes of an object being stored
+type PutOptions struct {
+	ContentType string
+	// SHA256 is the hex SHA-256 of the content, if known; backends that support it verify and record it
+	SHA256 string
+}
+
+// Storage is a backend that stores uploaded files as objects addressed by key
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any existing object
+	Put(ctx context.Context, key string, r io.Reader, size int64, opts PutOptions) error
+	// Get opens an object for streaming; the reader supports seeking for range requests
+	Get(ctx context.Context, key string) (io.ReadSeekCloser, *ObjectInfo, error)
+	// Delete removes an object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// Stat returns the attributes of an object, or ErrNotFound
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// List calls fn for every object whose key starts with prefix
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+}
+
+// Config holds the storage backend configuration
+type Config struct {
+	Driver string
+	// LocalPath is the root directory of the local driver
+	LocalPath string
+	S3        S3Config
+}
+
+// New creates the storage backend selected by the configuration
+func New(config Config) (Storage, error) {
+	switch config.Driver {
+	case DriverLocal, "":
+		return NewLocalStorage(config.LocalPath)
+	case DriverS3:
+		return NewS3Storage(config.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", config.Driver)
+	}
+}
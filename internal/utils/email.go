@@ -4,6 +4,7 @@ import (
 	"art-collection-system/internal/config"
 	"crypto/tls"
 	"fmt"
+	"html"
 
 	"gopkg.in/gomail.v2"
 )
@@ -45,6 +46,28 @@ func (s *EmailService) SendVerificationCode(to, code string) error {
 	return s.sendEmail(to, subject, body)
 }
 
+// SendCommentNotification tells an artwork's author about a new comment on the artwork
+func (s *EmailService) SendCommentNotification(to, authorName, artworkTitle, commenterName, content string) error {
+	subject := fmt.Sprintf("美术作品投稿系统 - 您的作品《%s》收到了新评论", artworkTitle)
+	body := fmt.Sprintf(`
+		<html>
+		<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+			<div style="max-width: 600px; margin: 0 auto; padding: 20px; border: 1px solid #ddd; border-radius: 5px;">
+				<h2 style="color: #4CAF50;">美术作品投稿系统</h2>
+				<p>%s，您好：</p>
+				<p><strong>%s</strong> 评论了您的作品《%s》：</p>
+				<div style="background-color: #f4f4f4; padding: 15px; margin: 20px 0; white-space: pre-wrap;">%s</div>
+				<p style="color: #999; font-size: 12px; margin-top: 30px;">
+					请登录系统查看和回复评论。
+				</p>
+			</div>
+		</body>
+		</html>
+	`, html.EscapeString(authorName), html.EscapeString(commenterName), html.EscapeString(artworkTitle), html.EscapeString(content))
+
+	return s.sendEmail(to, subject, body)
+}
+
 // sendEmail sends an email using SMTP
 func (s *EmailService) sendEmail(to, subject, body string) error {
 	m := gomail.NewMessage()
@@ -11,7 +11,7 @@ import (
 )
 
 const (
-	// MaxFileSize 最大文件大小 10MB
+	// MaxFileSize 默认最大文件大小 10MB（未配置 upload.max_size 时使用）
 	MaxFileSize = 10 * 1024 * 1024 // 10MB in bytes
 )
 
@@ -28,37 +28,61 @@ var (
 		"bmp":  {0x42, 0x4D},
 	}
 
-	ErrFileTooLarge      = errors.New("文件大小超过限制（最大 10MB）")
+	ErrFileTooLarge      = errors.New("文件大小超过限制")
 	ErrInvalidFileType   = errors.New("不支持的文件类型，仅允许图片格式")
 	ErrInvalidFileHeader = errors.New("文件内容与扩展名不匹配")
 )
 
 // ValidateImageFile 验证上传的图片文件
-// 检查文件大小、扩展名和文件内容
+// 检查文件大小（maxSize 为配置的上传大小上限，<= 0 时使用 MaxFileSize）、扩展名和文件内容
 // Requirements: 11.1
-func ValidateImageFile(fileHeader *multipart.FileHeader) error {
-	// 1. 检查文件大小
-	if fileHeader.Size > MaxFileSize {
-		return ErrFileTooLarge
+func ValidateImageFile(fileHeader *multipart.FileHeader, maxSize int64) error {
+	// 1. 检查文件大小和扩展名
+	if err := ValidateImageUploadInfo(fileHeader.Filename, fileHeader.Size, maxSize); err != nil {
+		return err
 	}
 
-	// 2. 检查文件扩展名
-	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-	if !isAllowedExtension(ext) {
-		return ErrInvalidFileType
-	}
-
-	// 3. 验证文件内容（防止伪造扩展名）
+	// 2. 验证文件内容（防止伪造扩展名）
 	file, err := fileHeader.Open()
 	if err != nil {
 		return fmt.Errorf("无法打开文件: %w", err)
 	}
 	defer file.Close()
 
+	return validateImageContent(file)
+}
+
+// ValidateImageReader 验证不经表单上传的图片文件（如断点续传组装完成的文件），检查项与 ValidateImageFile 相同
+// 读取 r 的开头部分，调用方需在之后自行回到文件开头
+func ValidateImageReader(filename string, size int64, r io.Reader, maxSize int64) error {
+	if err := ValidateImageUploadInfo(filename, size, maxSize); err != nil {
+		return err
+	}
+	return validateImageContent(r)
+}
+
+// ValidateImageUploadInfo 检查文件大小和扩展名，断点续传在接收文件内容之前用它检查声明的文件信息
+func ValidateImageUploadInfo(filename string, size int64, maxSize int64) error {
+	if maxSize <= 0 {
+		maxSize = MaxFileSize
+	}
+	if size > maxSize {
+		return fmt.Errorf("%w（最大 %s）", ErrFileTooLarge, FormatFileSize(maxSize))
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !isAllowedExtension(ext) {
+		return ErrInvalidFileType
+	}
+	return nil
+}
+
+// validateImageContent 通过文件魔数验证文件内容
+func validateImageContent(r io.Reader) error {
 	// 读取文件头（前 512 字节足够识别大多数文件类型）
 	buffer := make([]byte, 512)
-	n, err := file.Read(buffer)
-	if err != nil && err != io.EOF {
+	n, err := io.ReadFull(r, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		return fmt.Errorf("无法读取文件内容: %w", err)
 	}
 	buffer = buffer[:n]
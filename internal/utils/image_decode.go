@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+)
+
+// DefaultMaxImagePixels 未配置时允许解码的最大像素数（1 亿像素）
+const DefaultMaxImagePixels = 100_000_000
+
+// ErrImageTooLarge 图片像素数超过解码上限
+var ErrImageTooLarge = errors.New("图片像素数过多")
+
+var maxImagePixels int64 = DefaultMaxImagePixels
+
+// InitImageDecodeLimit 设置允许解码的最大像素数（宽 × 高），不大于 0 时使用默认值
+func InitImageDecodeLimit(maxPixels int64) {
+	if maxPixels <= 0 {
+		maxPixels = DefaultMaxImagePixels
+	}
+	maxImagePixels = maxPixels
+}
+
+// DecodeImage 解码图片，解码前先读取图片头中的尺寸
+// 像素数超过上限时不解码并返回 ErrImageTooLarge，避免很小的文件声明巨大尺寸耗尽内存
+func DecodeImage(data []byte) (image.Image, string, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, "", ErrInvalidImageData
+	}
+	if int64(config.Width)*int64(config.Height) > maxImagePixels {
+		return nil, "", fmt.Errorf("%w：%d×%d，最多 %d 像素", ErrImageTooLarge, config.Width, config.Height, maxImagePixels)
+	}
+	return image.Decode(bytes.NewReader(data))
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"math/bits"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// dHashWidth dHash 缩放后的宽度，相邻像素两两比较得到 8 位
+	dHashWidth = 9
+	// dHashHeight dHash 缩放后的高度
+	dHashHeight = 8
+	// dHashBits dHash 的总位数
+	dHashBits = dHashWidth*dHashHeight - dHashHeight
+)
+
+// ComputeContentHash 计算文件内容的 SHA-256，返回十六进制字符串
+func ComputeContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ComputeDHash 计算图片的差异哈希（dHash）
+// 图片缩放为 9x8 灰度图后比较每行相邻像素的亮度，左侧更亮记为 1
+// 缩放、重新压缩和轻微调色后的图片哈希值相近，可用汉明距离衡量相似度
+func ComputeDHash(img image.Image) uint64 {
+	gray := image.NewGray(image.Rect(0, 0, dHashWidth, dHashHeight))
+	draw.BiLinear.Scale(gray, gray.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	var hash uint64
+	for y := 0; y < dHashHeight; y++ {
+		for x := 0; x < dHashWidth-1; x++ {
+			hash <<= 1
+			if gray.GrayAt(x, y).Y > gray.GrayAt(x+1, y).Y {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// HashDistance 计算两个 dHash 的汉明距离
+func HashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// HashSimilarity 将汉明距离换算为 0-1 之间的相似度，1 表示完全相同
+func HashSimilarity(distance int) float64 {
+	if distance < 0 || distance > dHashBits {
+		return 0
+	}
+	return 1 - float64(distance)/float64(dHashBits)
+}
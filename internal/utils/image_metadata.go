@@ -0,0 +1,568 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+)
+
+const (
+	// sanitizedJPEGQuality 需要按方向旋转而重新编码 JPEG 时使用的质量
+	sanitizedJPEGQuality = 92
+
+	// maxMetadataValueBytes 单个未知二进制元数据值记录的最大字节数，超出时只记录长度
+	maxMetadataValueBytes = 64
+
+	// maxInflatedTextBytes PNG 压缩文本块解压后的最大字节数，超出时只记录压缩数据的长度
+	maxInflatedTextBytes = 64 * 1024
+)
+
+var (
+	ErrInvalidImageData = errors.New("图片文件已损坏或格式无法识别")
+
+	exifHeader = []byte("Exif\x00\x00")
+	xmpHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	pngMagic   = []byte("\x89PNG\r\n\x1a\n")
+
+	errInflatedTextTooLarge = errors.New("inflated text exceeds limit")
+)
+
+// SanitizedImage 是去除元数据后的图片
+type SanitizedImage struct {
+	// Data 去除元数据并按 EXIF 方向旋转后的文件内容
+	Data []byte
+	// Metadata 被去除的原始元数据（EXIF 标签名 -> 值，XMP 原文记录在 "XMP" 键下）
+	Metadata map[string]string
+}
+
+// SanitizeImage 去除图片中的 EXIF、XMP、IPTC 和文本元数据，并将 EXIF 方向应用到像素上
+// 支持 JPEG、PNG 和 WebP；WebP 只去除元数据，不做旋转；其他格式原样返回
+func SanitizeImage(data []byte) (*SanitizedImage, error) {
+	metadata := make(map[string]string)
+
+	var (
+		clean       []byte
+		orientation int
+		err         error
+	)
+	switch {
+	case bytes.HasPrefix(data, ImageMagicNumbers["jpeg"]):
+		clean, orientation, err = stripJPEGMetadata(data, metadata)
+		if err == nil && needsReorientation(orientation) {
+			clean, err = reorientImage(clean, orientation, "jpeg")
+		}
+	case bytes.HasPrefix(data, pngMagic):
+		clean, orientation, err = stripPNGMetadata(data, metadata)
+		if err == nil && needsReorientation(orientation) {
+			clean, err = reorientImage(clean, orientation, "png")
+		}
+	case len(data) >= 12 && bytes.HasPrefix(data, ImageMagicNumbers["webp"]) && string(data[8:12]) == "WEBP":
+		clean, err = stripWebPMetadata(data, metadata)
+	default:
+		clean = data
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &SanitizedImage{Data: clean, Metadata: metadata}, nil
+}
+
+// ImageOrientation 返回 JPEG 或 PNG 图片的 EXIF 方向，未设置或无法解析时返回 0
+func ImageOrientation(data []byte) int {
+	var orientation int
+	switch {
+	case bytes.HasPrefix(data, ImageMagicNumbers["jpeg"]):
+		_, orientation, _ = stripJPEGMetadata(data, make(map[string]string))
+	case bytes.HasPrefix(data, pngMagic):
+		_, orientation, _ = stripPNGMetadata(data, make(map[string]string))
+	}
+	return orientation
+}
+
+// IsTransposedOrientation 检查 EXIF 方向是否会交换图片的宽和高（方向 5-8）
+func IsTransposedOrientation(orientation int) bool {
+	return orientation >= 5 && orientation <= 8
+}
+
+// needsReorientation 检查 EXIF 方向是否需要旋转或翻转像素
+func needsReorientation(orientation int) bool {
+	return orientation >= 2 && orientation <= 8
+}
+
+// stripJPEGMetadata 去除 JPEG 中的 APP1（EXIF/XMP）、APP3-APP13、APP15 和注释段
+// 保留 APP0（JFIF）、APP2（ICC 色彩配置）和 APP14（Adobe 色彩变换）
+func stripJPEGMetadata(data []byte, metadata map[string]string) ([]byte, int, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	orientation := 0
+
+	pos := 2
+	for pos < len(data) {
+		if data[pos] != 0xFF || pos+1 >= len(data) {
+			return nil, 0, ErrInvalidImageData
+		}
+		marker := data[pos+1]
+
+		switch {
+		case marker == 0xFF:
+			// 填充字节
+			pos++
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// 扫描数据开始（或图像结束），其后的内容原样保留
+			out = append(out, data[pos:]...)
+			return out, orientation, nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// 无长度的独立标记
+			out = append(out, data[pos:pos+2]...)
+			pos += 2
+			continue
+		}
+
+		if pos+4 > len(data) {
+			return nil, 0, ErrInvalidImageData
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:pos+4]))
+		if end > len(data) || end < pos+4 {
+			return nil, 0, ErrInvalidImageData
+		}
+		payload := data[pos+4 : end]
+
+		switch {
+		case marker == 0xE1 && bytes.HasPrefix(payload, exifHeader):
+			orientation = parseEXIF(payload[len(exifHeader):], metadata)
+		case marker == 0xE1 && bytes.HasPrefix(payload, xmpHeader):
+			metadata["XMP"] = string(payload[len(xmpHeader):])
+		case marker == 0xE0 || marker == 0xE2 || marker == 0xEE:
+			out = append(out, data[pos:end]...)
+		case marker >= 0xE1 && marker <= 0xEF, marker == 0xFE:
+			// 其他应用段与注释段一律丢弃
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+
+	return nil, 0, ErrInvalidImageData
+}
+
+// stripPNGMetadata 去除 PNG 中的 eXIf、tEXt、zTXt、iTXt 和 tIME 块
+func stripPNGMetadata(data []byte, metadata map[string]string) ([]byte, int, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, pngMagic...)
+	orientation := 0
+
+	pos := len(pngMagic)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, 0, ErrInvalidImageData
+		}
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, 0, ErrInvalidImageData
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		chunkData := data[pos+8 : pos+8+length]
+
+		switch chunkType {
+		case "eXIf":
+			orientation = parseEXIF(chunkData, metadata)
+		case "tEXt":
+			if keyword, text, ok := bytes.Cut(chunkData, []byte{0}); ok {
+				metadata["PNG."+string(keyword)] = string(text)
+			}
+		case "zTXt":
+			if keyword, rest, ok := bytes.Cut(chunkData, []byte{0}); ok && len(rest) > 0 {
+				if text, err := inflate(rest[1:]); err == nil {
+					metadata["PNG."+string(keyword)] = string(text)
+				} else if errors.Is(err, errInflatedTextTooLarge) {
+					metadata["PNG."+string(keyword)] = compressedTextPlaceholder(rest[1:])
+				}
+			}
+		case "iTXt":
+			keyword, text, ok := parsePNGInternationalText(chunkData)
+			if ok {
+				if keyword == "XML:com.adobe.xmp" {
+					metadata["XMP"] = text
+				} else {
+					metadata["PNG."+keyword] = text
+				}
+			}
+		case "tIME":
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	return out, orientation, nil
+}
+
+// parsePNGInternationalText 解析 PNG iTXt 块，返回关键字和文本
+func parsePNGInternationalText(chunkData []byte) (string, string, bool) {
+	keyword, rest, ok := bytes.Cut(chunkData, []byte{0})
+	if !ok || len(rest) < 2 {
+		return "", "", false
+	}
+	compressed := rest[0] == 1
+	rest = rest[2:]
+
+	// 跳过语言标签和翻译后的关键字
+	for i := 0; i < 2; i++ {
+		if _, rest, ok = bytes.Cut(rest, []byte{0}); !ok {
+			return "", "", false
+		}
+	}
+
+	if compressed {
+		text, err := inflate(rest)
+		if errors.Is(err, errInflatedTextTooLarge) {
+			return string(keyword), compressedTextPlaceholder(rest), true
+		}
+		if err != nil {
+			return "", "", false
+		}
+		rest = text
+	}
+	return string(keyword), string(rest), true
+}
+
+// inflate 解压 zlib 数据，解压后超过 maxInflatedTextBytes 时停止并返回 errInflatedTextTooLarge
+func inflate(data []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	text, err := io.ReadAll(io.LimitReader(reader, maxInflatedTextBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(text) > maxInflatedTextBytes {
+		return nil, errInflatedTextTooLarge
+	}
+	return text, nil
+}
+
+// compressedTextPlaceholder 返回未解压的压缩文本在元数据中的记录值
+func compressedTextPlaceholder(compressed []byte) string {
+	return fmt.Sprintf("(compressed, %d bytes)", len(compressed))
+}
+
+// stripWebPMetadata 去除 WebP 中的 EXIF 和 XMP 块，并清除 VP8X 中对应的标志位
+func stripWebPMetadata(data []byte, metadata map[string]string) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+
+	pos := 12
+	for pos+8 <= len(data) {
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		end := pos + 8 + size + size%2
+		if size < 0 || pos+8+size > len(data) {
+			return nil, ErrInvalidImageData
+		}
+		if end > len(data) {
+			end = len(data)
+		}
+		chunkData := data[pos+8 : pos+8+size]
+
+		switch fourCC {
+		case "EXIF":
+			parseEXIF(bytes.TrimPrefix(chunkData, exifHeader), metadata)
+		case "XMP ":
+			metadata["XMP"] = string(chunkData)
+		case "VP8X":
+			chunk := append([]byte(nil), data[pos:end]...)
+			if size > 0 {
+				// 第 3 位表示含 EXIF，第 2 位表示含 XMP
+				chunk[8] &^= 0x08 | 0x04
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
+
+// reorientImage 按 EXIF 方向旋转或翻转图片，并重新编码为原格式
+func reorientImage(data []byte, orientation int, format string) ([]byte, error) {
+	img, _, err := DecodeImage(data)
+	if errors.Is(err, ErrImageTooLarge) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, ErrInvalidImageData
+	}
+
+	oriented := applyOrientation(img, orientation)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, oriented, &jpeg.Options{Quality: sanitizedJPEGQuality})
+	} else {
+		err = png.Encode(&buf, oriented)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("重新编码图片失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// applyOrientation 将 EXIF 方向（2-8）应用到像素上，返回方向为 1 的图片
+func applyOrientation(img image.Image, orientation int) image.Image {
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	w, h := bounds.Dx(), bounds.Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			si := src.PixOffset(x, y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+
+	return dst
+}
+
+// exifTagNames 常见 EXIF 标签名，按所在 IFD 分组；未列出的标签记录为 "{IFD}.0x{tag}"
+var exifTagNames = map[string]map[uint16]string{
+	"IFD0": {
+		0x010E: "ImageDescription",
+		0x010F: "Make",
+		0x0110: "Model",
+		0x0112: "Orientation",
+		0x011A: "XResolution",
+		0x011B: "YResolution",
+		0x0128: "ResolutionUnit",
+		0x0131: "Software",
+		0x0132: "DateTime",
+		0x013B: "Artist",
+		0x8298: "Copyright",
+	},
+	"Exif": {
+		0x829A: "ExposureTime",
+		0x829D: "FNumber",
+		0x8827: "ISOSpeedRatings",
+		0x9003: "DateTimeOriginal",
+		0x9004: "DateTimeDigitized",
+		0x9010: "OffsetTime",
+		0x9011: "OffsetTimeOriginal",
+		0x9209: "Flash",
+		0x920A: "FocalLength",
+		0xA002: "PixelXDimension",
+		0xA003: "PixelYDimension",
+		0xA420: "ImageUniqueID",
+		0xA430: "CameraOwnerName",
+		0xA431: "BodySerialNumber",
+		0xA432: "LensSpecification",
+		0xA433: "LensMake",
+		0xA434: "LensModel",
+		0xA435: "LensSerialNumber",
+	},
+	"GPS": {
+		0x0000: "GPSVersionID",
+		0x0001: "GPSLatitudeRef",
+		0x0002: "GPSLatitude",
+		0x0003: "GPSLongitudeRef",
+		0x0004: "GPSLongitude",
+		0x0005: "GPSAltitudeRef",
+		0x0006: "GPSAltitude",
+		0x0007: "GPSTimeStamp",
+		0x0010: "GPSImgDirectionRef",
+		0x0011: "GPSImgDirection",
+		0x001D: "GPSDateStamp",
+	},
+}
+
+const (
+	exifTagOrientation = 0x0112
+	exifTagExifIFD     = 0x8769
+	exifTagGPSIFD      = 0x8825
+	exifTagMakerNote   = 0x927C
+)
+
+// exifTypeSizes EXIF 数据类型对应的单个值字节数
+var exifTypeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// exifReader 读取 TIFF 结构的 EXIF 数据
+type exifReader struct {
+	data    []byte
+	order   binary.ByteOrder
+	visited map[uint32]bool
+}
+
+// parseEXIF 解析 TIFF 结构的 EXIF 数据，将标签写入 metadata 并返回方向（未设置时为 0）
+// 损坏的 EXIF 数据会被忽略，不影响元数据的去除
+func parseEXIF(data []byte, metadata map[string]string) int {
+	if len(data) < 8 {
+		return 0
+	}
+
+	r := &exifReader{data: data, visited: make(map[uint32]bool)}
+	switch string(data[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	orientation := 0
+	r.readIFD(r.order.Uint32(data[4:8]), "IFD0", metadata, &orientation)
+	return orientation
+}
+
+// readIFD 读取一个 IFD 中的全部标签，并递归读取 Exif 和 GPS 子 IFD
+func (r *exifReader) readIFD(offset uint32, group string, metadata map[string]string, orientation *int) {
+	if r.visited[offset] || int(offset)+2 > len(r.data) {
+		return
+	}
+	r.visited[offset] = true
+
+	count := int(r.order.Uint16(r.data[offset:]))
+	for i := 0; i < count; i++ {
+		entry := int(offset) + 2 + i*12
+		if entry+12 > len(r.data) {
+			return
+		}
+		tag := r.order.Uint16(r.data[entry:])
+		typ := r.order.Uint16(r.data[entry+2:])
+		n := r.order.Uint32(r.data[entry+4:])
+
+		value, ok := r.entryValue(entry, typ, n)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case group == "IFD0" && tag == exifTagExifIFD && len(value) >= 4:
+			r.readIFD(r.order.Uint32(value), "Exif", metadata, orientation)
+			continue
+		case group == "IFD0" && tag == exifTagGPSIFD && len(value) >= 4:
+			r.readIFD(r.order.Uint32(value), "GPS", metadata, orientation)
+			continue
+		case group == "Exif" && tag == exifTagMakerNote:
+			// 厂商私有数据体积大且格式不公开，只记录长度
+			metadata["MakerNote"] = fmt.Sprintf("(%d bytes)", len(value))
+			continue
+		case group == "IFD0" && tag == exifTagOrientation && typ == 3 && len(value) >= 2:
+			*orientation = int(r.order.Uint16(value))
+		}
+
+		name, known := exifTagNames[group][tag]
+		if !known {
+			name = fmt.Sprintf("%s.0x%04X", group, tag)
+		}
+		metadata[name] = r.formatValue(typ, value)
+	}
+}
+
+// entryValue 返回 IFD 条目的原始值字节
+func (r *exifReader) entryValue(entry int, typ uint16, count uint32) ([]byte, bool) {
+	size, ok := exifTypeSizes[typ]
+	if !ok {
+		return nil, false
+	}
+	total := uint64(size) * uint64(count)
+	if total <= 4 {
+		return r.data[entry+8 : entry+8+int(total)], true
+	}
+
+	offset := uint64(r.order.Uint32(r.data[entry+8:]))
+	if offset+total > uint64(len(r.data)) {
+		return nil, false
+	}
+	return r.data[offset : offset+total], true
+}
+
+// formatValue 将 EXIF 值格式化为字符串
+func (r *exifReader) formatValue(typ uint16, value []byte) string {
+	switch typ {
+	case 2:
+		return strings.TrimRight(string(value), "\x00 ")
+	case 1, 6:
+		parts := make([]string, 0, len(value))
+		for _, b := range value {
+			parts = append(parts, fmt.Sprintf("%d", b))
+		}
+		return strings.Join(parts, ",")
+	case 3, 8:
+		parts := make([]string, 0, len(value)/2)
+		for i := 0; i+2 <= len(value); i += 2 {
+			parts = append(parts, fmt.Sprintf("%d", r.order.Uint16(value[i:])))
+		}
+		return strings.Join(parts, ",")
+	case 4, 9:
+		parts := make([]string, 0, len(value)/4)
+		for i := 0; i+4 <= len(value); i += 4 {
+			if typ == 9 {
+				parts = append(parts, fmt.Sprintf("%d", int32(r.order.Uint32(value[i:]))))
+			} else {
+				parts = append(parts, fmt.Sprintf("%d", r.order.Uint32(value[i:])))
+			}
+		}
+		return strings.Join(parts, ",")
+	case 5, 10:
+		parts := make([]string, 0, len(value)/8)
+		for i := 0; i+8 <= len(value); i += 8 {
+			if typ == 10 {
+				parts = append(parts, fmt.Sprintf("%d/%d", int32(r.order.Uint32(value[i:])), int32(r.order.Uint32(value[i+4:]))))
+			} else {
+				parts = append(parts, fmt.Sprintf("%d/%d", r.order.Uint32(value[i:]), r.order.Uint32(value[i+4:])))
+			}
+		}
+		return strings.Join(parts, ",")
+	default:
+		if len(value) > maxMetadataValueBytes {
+			return fmt.Sprintf("(%d bytes)", len(value))
+		}
+		return hex.EncodeToString(value)
+	}
+}
@@ -0,0 +1,201 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 表格导出格式
+const (
+	TableFormatCSV  = "csv"
+	TableFormatXLSX = "xlsx"
+)
+
+// tableTimeLayout 导出表格中时间的格式
+const tableTimeLayout = "2006-01-02 15:04:05"
+
+// TableWriter 逐行写出表格，数据不在内存中累积
+type TableWriter interface {
+	// WriteRow 写出一行，单元格可以是字符串、整数、浮点数、时间或 nil
+	WriteRow(cells []interface{}) error
+	// Close 写出剩余内容，不关闭底层 io.Writer
+	Close() error
+}
+
+// NewTableWriter 按格式创建表格写出器
+func NewTableWriter(format string, w io.Writer) (TableWriter, error) {
+	switch format {
+	case TableFormatCSV:
+		return newCSVTableWriter(w)
+	case TableFormatXLSX:
+		return newXLSXTableWriter(w)
+	default:
+		return nil, fmt.Errorf("不支持的导出格式: %s", format)
+	}
+}
+
+// IsValidTableFormat 判断导出格式是否受支持
+func IsValidTableFormat(format string) bool {
+	return format == TableFormatCSV || format == TableFormatXLSX
+}
+
+// TableContentType 返回导出格式对应的 Content-Type
+func TableContentType(format string) string {
+	if format == TableFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// formatTableCell 将单元格转换为文本
+func formatTableCell(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Local().Format(tableTimeLayout)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return formatTableCell(*v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// isNumericCell 判断单元格是否应写为数字
+func isNumericCell(cell interface{}) bool {
+	switch cell.(type) {
+	case int, int64, uint, uint64, float64:
+		return true
+	}
+	return false
+}
+
+// csvTableWriter 写出 CSV 表格
+type csvTableWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVTableWriter(w io.Writer) (*csvTableWriter, error) {
+	// 写出 UTF-8 BOM，使表格软件正确识别中文
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	return &csvTableWriter{writer: csv.NewWriter(w)}, nil
+}
+
+func (t *csvTableWriter) WriteRow(cells []interface{}) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		value := formatTableCell(cell)
+		// 以公式字符开头的文本加单引号，防止表格软件将其作为公式执行
+		if _, ok := cell.(string); ok && value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			value = "'" + value
+		}
+		record[i] = value
+	}
+	return t.writer.Write(record)
+}
+
+func (t *csvTableWriter) Close() error {
+	t.writer.Flush()
+	return t.writer.Error()
+}
+
+// xlsxTableWriter 以流式方式写出只有一个工作表的 XLSX 表格
+// 文本使用内联字符串，无需先收集共享字符串表
+type xlsxTableWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+}
+
+// xlsxStaticParts XLSX 中除工作表外的固定部分
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`},
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/></cellXfs></styleSheet>`},
+}
+
+func newXLSXTableWriter(w io.Writer) (*xlsxTableWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		entry, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(entry, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// 工作表必须是最后一个条目，才能边生成边写出
+	entry, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(entry)
+	_, err = sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxTableWriter{archive: archive, sheet: sheet}, nil
+}
+
+func (t *xlsxTableWriter) WriteRow(cells []interface{}) error {
+	t.sheet.WriteString("<row>")
+	for _, cell := range cells {
+		value := formatTableCell(cell)
+		if isNumericCell(cell) {
+			t.sheet.WriteString("<c><v>")
+			t.sheet.WriteString(value)
+			t.sheet.WriteString("</v></c>")
+			continue
+		}
+		t.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		// EscapeText 会将 XML 不允许的字符替换为 U+FFFD
+		if err := xml.EscapeText(t.sheet, []byte(value)); err != nil {
+			return err
+		}
+		t.sheet.WriteString("</t></is></c>")
+	}
+	_, err := t.sheet.WriteString("</row>")
+	return err
+}
+
+func (t *xlsxTableWriter) Close() error {
+	if _, err := t.sheet.WriteString("</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	if err := t.sheet.Flush(); err != nil {
+		return err
+	}
+	return t.archive.Close()
+}
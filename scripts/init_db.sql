@@ -26,6 +26,22 @@ CREATE TABLE IF NOT EXISTS `activities` (
   `deadline` datetime(3) DEFAULT NULL,
   `description` text,
   `max_uploads_per_user` int NOT NULL DEFAULT '5',
+  `scoring_closed` tinyint(1) NOT NULL DEFAULT '0',
+  `gallery_enabled` tinyint(1) NOT NULL DEFAULT '0',
+  `gallery_opens_at` datetime(3) DEFAULT NULL,
+  `voting_enabled` tinyint(1) NOT NULL DEFAULT '0',
+  `voting_opens_at` datetime(3) DEFAULT NULL,
+  `voting_closes_at` datetime(3) DEFAULT NULL,
+  `max_votes_per_user` bigint NOT NULL DEFAULT '0',
+  `image_allowed_formats` json DEFAULT NULL,
+  `image_min_bytes` bigint NOT NULL DEFAULT '0',
+  `image_max_bytes` bigint NOT NULL DEFAULT '0',
+  `image_min_width` bigint NOT NULL DEFAULT '0',
+  `image_max_width` bigint NOT NULL DEFAULT '0',
+  `image_min_height` bigint NOT NULL DEFAULT '0',
+  `image_max_height` bigint NOT NULL DEFAULT '0',
+  `image_min_aspect_ratio` double NOT NULL DEFAULT '0',
+  `image_max_aspect_ratio` double NOT NULL DEFAULT '0',
   `is_deleted` tinyint(1) NOT NULL DEFAULT '0',
   `created_at` datetime(3) DEFAULT NULL,
   `updated_at` datetime(3) DEFAULT NULL,
@@ -33,26 +49,217 @@ CREATE TABLE IF NOT EXISTS `activities` (
   KEY `idx_activities_is_deleted` (`is_deleted`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
+-- 创建文件内容表（按 SHA-256 去重存储，ref_count 为引用该文件的作品和历史版本数）
+CREATE TABLE IF NOT EXISTS `blobs` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `hash` varchar(64) NOT NULL,
+  `path` varchar(500) NOT NULL,
+  `size` bigint NOT NULL,
+  `ref_count` int NOT NULL DEFAULT '0',
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_blobs_hash` (`hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
 -- 创建作品表
 CREATE TABLE IF NOT EXISTS `artworks` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT,
   `activity_id` bigint unsigned NOT NULL,
   `user_id` bigint unsigned NOT NULL,
+  `blob_id` bigint unsigned DEFAULT NULL,
   `file_path` varchar(500) NOT NULL,
   `file_name` varchar(255) NOT NULL,
-  `review_status` enum('pending','approved') NOT NULL DEFAULT 'pending',
+  `title` varchar(200) NOT NULL DEFAULT '',
+  `description` text,
+  `medium` varchar(100) DEFAULT NULL,
+  `width_cm` double DEFAULT NULL,
+  `height_cm` double DEFAULT NULL,
+  `depth_cm` double DEFAULT NULL,
+  `creation_year` bigint DEFAULT NULL,
+  `tags` json DEFAULT NULL,
+  `page_count` int NOT NULL DEFAULT '1',
+  `vote_count` bigint NOT NULL DEFAULT '0',
+  `revision` int NOT NULL DEFAULT '1',
+  `file_updated_at` datetime(3) DEFAULT NULL,
+  `original_metadata` json DEFAULT NULL,
+  `content_hash` varchar(64) NOT NULL DEFAULT '',
+  `perceptual_hash` bigint unsigned DEFAULT NULL,
+  `duplicate_of_id` bigint unsigned DEFAULT NULL,
+  `review_status` enum('draft','pending','approved','rejected') NOT NULL DEFAULT 'pending',
+  `reject_reason` text,
+  `reject_reason_codes` json DEFAULT NULL,
+  `is_deleted` tinyint(1) NOT NULL DEFAULT '0',
+  `deleted_at` datetime(3) DEFAULT NULL,
   `created_at` datetime(3) DEFAULT NULL,
   `updated_at` datetime(3) DEFAULT NULL,
   PRIMARY KEY (`id`),
   KEY `idx_activity_id` (`activity_id`),
   KEY `idx_user_id` (`user_id`),
   KEY `idx_user_activity` (`user_id`,`activity_id`),
+  KEY `idx_activity_content_hash` (`activity_id`,`content_hash`),
+  KEY `idx_artworks_content_hash` (`content_hash`),
   KEY `idx_review_status` (`review_status`),
   KEY `idx_artworks_created_at` (`created_at`),
+  KEY `idx_artworks_blob_id` (`blob_id`),
+  KEY `idx_artworks_is_deleted` (`is_deleted`),
+  KEY `idx_artworks_deleted_at` (`deleted_at`),
   CONSTRAINT `fk_activities_artworks` FOREIGN KEY (`activity_id`) REFERENCES `activities` (`id`),
+  CONSTRAINT `fk_blobs_artworks` FOREIGN KEY (`blob_id`) REFERENCES `blobs` (`id`),
   CONSTRAINT `fk_users_artworks` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
+-- 创建上传配额表（检查上传数量限制并创建作品时锁定对应行，防止并发上传超出限制）
+CREATE TABLE IF NOT EXISTS `upload_quotas` (
+  `user_id` bigint unsigned NOT NULL,
+  `activity_id` bigint unsigned NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`user_id`,`activity_id`),
+  KEY `idx_upload_quotas_activity_id` (`activity_id`),
+  CONSTRAINT `fk_upload_quotas_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_upload_quotas_activity` FOREIGN KEY (`activity_id`) REFERENCES `activities` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建作品页面表（多页作品第 2 页起的图片，作品自身的文件为第 1 页）
+CREATE TABLE IF NOT EXISTS `artwork_pages` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `artwork_id` bigint unsigned NOT NULL,
+  `page` int NOT NULL,
+  `blob_id` bigint unsigned DEFAULT NULL,
+  `file_path` varchar(500) NOT NULL,
+  `file_name` varchar(255) NOT NULL,
+  `original_metadata` json DEFAULT NULL,
+  `content_hash` varchar(64) NOT NULL DEFAULT '',
+  `perceptual_hash` bigint unsigned DEFAULT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_artwork_page` (`artwork_id`,`page`),
+  KEY `idx_artwork_pages_blob_id` (`blob_id`),
+  CONSTRAINT `fk_artworks_pages` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_blobs_artwork_pages` FOREIGN KEY (`blob_id`) REFERENCES `blobs` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建作品历史版本表
+CREATE TABLE IF NOT EXISTS `artwork_revisions` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `artwork_id` bigint unsigned NOT NULL,
+  `revision` int NOT NULL,
+  `page` int NOT NULL DEFAULT '1',
+  `blob_id` bigint unsigned DEFAULT NULL,
+  `file_path` varchar(500) NOT NULL,
+  `file_name` varchar(255) NOT NULL,
+  `uploaded_at` datetime(3) NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_artwork_revision` (`artwork_id`,`revision`),
+  KEY `idx_artwork_revisions_blob_id` (`blob_id`),
+  CONSTRAINT `fk_artwork_revisions_artwork` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_blobs_artwork_revisions` FOREIGN KEY (`blob_id`) REFERENCES `blobs` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建作品审核记录表
+CREATE TABLE IF NOT EXISTS `artwork_reviews` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `artwork_id` bigint unsigned NOT NULL,
+  `reviewer_id` bigint unsigned DEFAULT NULL,
+  `previous_status` enum('draft','pending','approved','rejected') NOT NULL,
+  `new_status` enum('pending','approved','rejected') NOT NULL,
+  `comment` text,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_artwork_reviews_artwork` (`artwork_id`),
+  KEY `idx_artwork_reviews_reviewer` (`reviewer_id`),
+  KEY `idx_artwork_reviews_created_at` (`created_at`),
+  CONSTRAINT `fk_artwork_reviews_artwork` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_artwork_reviews_reviewer` FOREIGN KEY (`reviewer_id`) REFERENCES `users` (`id`) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建评分标准表
+CREATE TABLE IF NOT EXISTS `rubric_criteria` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `activity_id` bigint unsigned NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `description` text,
+  `weight` double NOT NULL,
+  `min_score` double NOT NULL DEFAULT '0',
+  `max_score` double NOT NULL DEFAULT '10',
+  `sort_order` int NOT NULL DEFAULT '0',
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_rubric_activity` (`activity_id`),
+  CONSTRAINT `fk_rubric_criteria_activity` FOREIGN KEY (`activity_id`) REFERENCES `activities` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建活动评委表
+CREATE TABLE IF NOT EXISTS `activity_judges` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `activity_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_activity_judge` (`activity_id`,`user_id`),
+  KEY `idx_activity_judges_user_id` (`user_id`),
+  CONSTRAINT `fk_activity_judges_activity` FOREIGN KEY (`activity_id`) REFERENCES `activities` (`id`),
+  CONSTRAINT `fk_activity_judges_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建作品评分表
+CREATE TABLE IF NOT EXISTS `artwork_scores` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `artwork_id` bigint unsigned NOT NULL,
+  `criterion_id` bigint unsigned NOT NULL,
+  `judge_id` bigint unsigned NOT NULL,
+  `score` double NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_artwork_criterion_judge` (`artwork_id`,`criterion_id`,`judge_id`),
+  KEY `idx_artwork_scores_judge_id` (`judge_id`),
+  CONSTRAINT `fk_artwork_scores_artwork` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_artwork_scores_criterion` FOREIGN KEY (`criterion_id`) REFERENCES `rubric_criteria` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_artwork_scores_judge` FOREIGN KEY (`judge_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建公众投票表（每个用户对每件作品只能投一票，artworks.vote_count 由服务端定时汇总）
+CREATE TABLE IF NOT EXISTS `votes` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `activity_id` bigint unsigned NOT NULL,
+  `artwork_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_vote_artwork_user` (`artwork_id`,`user_id`),
+  KEY `idx_votes_activity_user` (`activity_id`,`user_id`),
+  CONSTRAINT `fk_votes_activity` FOREIGN KEY (`activity_id`) REFERENCES `activities` (`id`),
+  CONSTRAINT `fk_votes_artwork` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_votes_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建作品评论表（root_id 指向讨论串的第一条评论，删除评论时其下的回复一并删除）
+CREATE TABLE IF NOT EXISTS `comments` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `artwork_id` bigint unsigned NOT NULL,
+  `root_id` bigint unsigned DEFAULT NULL,
+  `parent_id` bigint unsigned DEFAULT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `content` text NOT NULL,
+  `is_hidden` tinyint(1) NOT NULL DEFAULT '0',
+  `hidden_by` bigint unsigned DEFAULT NULL,
+  `hidden_at` datetime(3) DEFAULT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_comments_artwork_root` (`artwork_id`,`root_id`),
+  KEY `idx_comments_parent_id` (`parent_id`),
+  KEY `idx_comments_user_id` (`user_id`),
+  KEY `idx_comments_is_hidden` (`is_hidden`),
+  KEY `idx_comments_created_at` (`created_at`),
+  CONSTRAINT `fk_comments_artwork` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_comments_parent` FOREIGN KEY (`parent_id`) REFERENCES `comments` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_comments_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
 -- 插入默认管理员账户
 -- 邮箱: admin@example.com
 -- 密码: Admin123456
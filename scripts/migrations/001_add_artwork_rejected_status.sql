@@ -0,0 +1,9 @@
+-- 为作品增加"已驳回"审核状态及驳回原因
+-- 适用于在此变更之前通过 init_db.sql 初始化的数据库
+
+USE art_collection;
+
+ALTER TABLE `artworks`
+  MODIFY COLUMN `review_status` enum('pending','approved','rejected') NOT NULL DEFAULT 'pending',
+  ADD COLUMN `reject_reason` text AFTER `review_status`,
+  ADD COLUMN `reject_reason_codes` json DEFAULT NULL AFTER `reject_reason`;
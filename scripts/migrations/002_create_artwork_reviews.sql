@@ -0,0 +1,19 @@
+-- 创建作品审核记录表，记录每一次审核决定
+
+USE art_collection;
+
+CREATE TABLE IF NOT EXISTS `artwork_reviews` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `artwork_id` bigint unsigned NOT NULL,
+  `reviewer_id` bigint unsigned DEFAULT NULL,
+  `previous_status` enum('pending','approved','rejected') NOT NULL,
+  `new_status` enum('pending','approved','rejected') NOT NULL,
+  `comment` text,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_artwork_reviews_artwork` (`artwork_id`),
+  KEY `idx_artwork_reviews_reviewer` (`reviewer_id`),
+  KEY `idx_artwork_reviews_created_at` (`created_at`),
+  CONSTRAINT `fk_artwork_reviews_artwork` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_artwork_reviews_reviewer` FOREIGN KEY (`reviewer_id`) REFERENCES `users` (`id`) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
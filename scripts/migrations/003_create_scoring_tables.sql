@@ -0,0 +1,53 @@
+-- 为活动增加多评委评分：评分标准、评委分配和作品评分
+
+USE art_collection;
+
+ALTER TABLE `activities`
+  ADD COLUMN `scoring_closed` tinyint(1) NOT NULL DEFAULT '0' AFTER `max_uploads_per_user`;
+
+-- 创建评分标准表
+CREATE TABLE IF NOT EXISTS `rubric_criteria` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `activity_id` bigint unsigned NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `description` text,
+  `weight` double NOT NULL,
+  `min_score` double NOT NULL DEFAULT '0',
+  `max_score` double NOT NULL DEFAULT '10',
+  `sort_order` int NOT NULL DEFAULT '0',
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_rubric_activity` (`activity_id`),
+  CONSTRAINT `fk_rubric_criteria_activity` FOREIGN KEY (`activity_id`) REFERENCES `activities` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建活动评委表
+CREATE TABLE IF NOT EXISTS `activity_judges` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `activity_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_activity_judge` (`activity_id`,`user_id`),
+  KEY `idx_activity_judges_user_id` (`user_id`),
+  CONSTRAINT `fk_activity_judges_activity` FOREIGN KEY (`activity_id`) REFERENCES `activities` (`id`),
+  CONSTRAINT `fk_activity_judges_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建作品评分表
+CREATE TABLE IF NOT EXISTS `artwork_scores` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `artwork_id` bigint unsigned NOT NULL,
+  `criterion_id` bigint unsigned NOT NULL,
+  `judge_id` bigint unsigned NOT NULL,
+  `score` double NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_artwork_criterion_judge` (`artwork_id`,`criterion_id`,`judge_id`),
+  KEY `idx_artwork_scores_judge_id` (`judge_id`),
+  CONSTRAINT `fk_artwork_scores_artwork` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_artwork_scores_criterion` FOREIGN KEY (`criterion_id`) REFERENCES `rubric_criteria` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_artwork_scores_judge` FOREIGN KEY (`judge_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,20 @@
+-- 支持替换作品文件并保留历史版本
+
+USE art_collection;
+
+ALTER TABLE `artworks`
+  ADD COLUMN `revision` int NOT NULL DEFAULT '1' AFTER `file_name`,
+  ADD COLUMN `file_updated_at` datetime(3) DEFAULT NULL AFTER `revision`;
+
+CREATE TABLE IF NOT EXISTS `artwork_revisions` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `artwork_id` bigint unsigned NOT NULL,
+  `revision` int NOT NULL,
+  `file_path` varchar(500) NOT NULL,
+  `file_name` varchar(255) NOT NULL,
+  `uploaded_at` datetime(3) NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_artwork_revision` (`artwork_id`,`revision`),
+  CONSTRAINT `fk_artwork_revisions_artwork` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
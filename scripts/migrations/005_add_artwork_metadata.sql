@@ -0,0 +1,20 @@
+-- 为作品增加标题、描述、创作媒介、尺寸、创作年份和标签
+
+USE art_collection;
+
+ALTER TABLE `artworks`
+  ADD COLUMN `title` varchar(200) NOT NULL DEFAULT '' AFTER `file_name`,
+  ADD COLUMN `description` text AFTER `title`,
+  ADD COLUMN `medium` varchar(100) DEFAULT NULL AFTER `description`,
+  ADD COLUMN `width_cm` double DEFAULT NULL AFTER `medium`,
+  ADD COLUMN `height_cm` double DEFAULT NULL AFTER `width_cm`,
+  ADD COLUMN `depth_cm` double DEFAULT NULL AFTER `height_cm`,
+  ADD COLUMN `creation_year` bigint DEFAULT NULL AFTER `depth_cm`,
+  ADD COLUMN `tags` json DEFAULT NULL AFTER `creation_year`;
+
+-- 已有作品使用去掉扩展名的文件名作为标题
+UPDATE `artworks`
+SET `title` = IF(LOCATE('.', `file_name`) > 0,
+                 SUBSTRING(`file_name`, 1, CHAR_LENGTH(`file_name`) - LOCATE('.', REVERSE(`file_name`))),
+                 `file_name`)
+WHERE `title` = '';
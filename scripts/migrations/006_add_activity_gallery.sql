@@ -0,0 +1,7 @@
+-- 为活动增加公开作品展示开关和展示开放时间
+
+USE art_collection;
+
+ALTER TABLE `activities`
+  ADD COLUMN `gallery_enabled` tinyint(1) NOT NULL DEFAULT '0' AFTER `scoring_closed`,
+  ADD COLUMN `gallery_opens_at` datetime(3) DEFAULT NULL AFTER `gallery_enabled`;
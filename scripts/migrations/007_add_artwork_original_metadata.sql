@@ -0,0 +1,6 @@
+-- 为作品增加原始图片元数据字段（上传时去除的 EXIF/XMP 等信息，仅在配置开启时记录）
+
+USE art_collection;
+
+ALTER TABLE `artworks`
+  ADD COLUMN `original_metadata` json DEFAULT NULL AFTER `file_updated_at`;
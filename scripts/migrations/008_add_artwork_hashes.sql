@@ -0,0 +1,11 @@
+-- 为作品增加文件 SHA-256、感知哈希（dHash）和重复标记，用于重复投稿检测
+-- 执行后请运行 go run ./cmd/backfill-hashes 为已有作品计算哈希
+
+USE art_collection;
+
+ALTER TABLE `artworks`
+  ADD COLUMN `content_hash` varchar(64) NOT NULL DEFAULT '' AFTER `original_metadata`,
+  ADD COLUMN `perceptual_hash` bigint unsigned DEFAULT NULL AFTER `content_hash`,
+  ADD COLUMN `duplicate_of_id` bigint unsigned DEFAULT NULL AFTER `perceptual_hash`,
+  ADD KEY `idx_activity_content_hash` (`activity_id`,`content_hash`),
+  ADD KEY `idx_artworks_content_hash` (`content_hash`);
@@ -0,0 +1,14 @@
+-- 为活动增加图片限制：允许的格式、文件大小、像素尺寸和宽高比（0 表示不限制）
+
+USE art_collection;
+
+ALTER TABLE `activities`
+  ADD COLUMN `image_allowed_formats` json DEFAULT NULL AFTER `gallery_opens_at`,
+  ADD COLUMN `image_min_bytes` bigint NOT NULL DEFAULT '0' AFTER `image_allowed_formats`,
+  ADD COLUMN `image_max_bytes` bigint NOT NULL DEFAULT '0' AFTER `image_min_bytes`,
+  ADD COLUMN `image_min_width` bigint NOT NULL DEFAULT '0' AFTER `image_max_bytes`,
+  ADD COLUMN `image_max_width` bigint NOT NULL DEFAULT '0' AFTER `image_min_width`,
+  ADD COLUMN `image_min_height` bigint NOT NULL DEFAULT '0' AFTER `image_max_width`,
+  ADD COLUMN `image_max_height` bigint NOT NULL DEFAULT '0' AFTER `image_min_height`,
+  ADD COLUMN `image_min_aspect_ratio` double NOT NULL DEFAULT '0' AFTER `image_max_height`,
+  ADD COLUMN `image_max_aspect_ratio` double NOT NULL DEFAULT '0' AFTER `image_min_aspect_ratio`;
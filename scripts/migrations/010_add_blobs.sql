@@ -0,0 +1,26 @@
+-- 按内容 SHA-256 去重存储作品文件：新增 blobs 表，作品和历史版本通过 blob_id 引用文件
+-- 已有作品的 blob_id 为空，仍使用原有文件；执行后可运行 go run ./cmd/backfill-blobs 将其转为去重存储
+
+USE art_collection;
+
+CREATE TABLE IF NOT EXISTS `blobs` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `hash` varchar(64) NOT NULL,
+  `path` varchar(500) NOT NULL,
+  `size` bigint NOT NULL,
+  `ref_count` int NOT NULL DEFAULT '0',
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_blobs_hash` (`hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE `artworks`
+  ADD COLUMN `blob_id` bigint unsigned DEFAULT NULL AFTER `user_id`,
+  ADD KEY `idx_artworks_blob_id` (`blob_id`),
+  ADD CONSTRAINT `fk_blobs_artworks` FOREIGN KEY (`blob_id`) REFERENCES `blobs` (`id`);
+
+ALTER TABLE `artwork_revisions`
+  ADD COLUMN `blob_id` bigint unsigned DEFAULT NULL AFTER `revision`,
+  ADD KEY `idx_artwork_revisions_blob_id` (`blob_id`),
+  ADD CONSTRAINT `fk_blobs_artwork_revisions` FOREIGN KEY (`blob_id`) REFERENCES `blobs` (`id`);
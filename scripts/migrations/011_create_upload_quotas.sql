@@ -0,0 +1,14 @@
+-- 上传数量限制并发控制：每个用户在每个活动中对应一行，检查上传数量并创建作品时锁定该行
+-- 无需回填数据，行在首次上传时创建
+
+USE art_collection;
+
+CREATE TABLE IF NOT EXISTS `upload_quotas` (
+  `user_id` bigint unsigned NOT NULL,
+  `activity_id` bigint unsigned NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`user_id`,`activity_id`),
+  KEY `idx_upload_quotas_activity_id` (`activity_id`),
+  CONSTRAINT `fk_upload_quotas_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_upload_quotas_activity` FOREIGN KEY (`activity_id`) REFERENCES `activities` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
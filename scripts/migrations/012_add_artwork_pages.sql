@@ -0,0 +1,29 @@
+-- 多页作品（如漫画、组图）：作品自身的文件为第 1 页，其余页面保存在 artwork_pages 表中
+-- 已有作品均为单页，page_count 默认为 1，已有文件修订均属于第 1 页，无需回填数据
+
+USE art_collection;
+
+ALTER TABLE `artworks`
+  ADD COLUMN `page_count` int NOT NULL DEFAULT '1' AFTER `tags`;
+
+CREATE TABLE IF NOT EXISTS `artwork_pages` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `artwork_id` bigint unsigned NOT NULL,
+  `page` int NOT NULL,
+  `blob_id` bigint unsigned DEFAULT NULL,
+  `file_path` varchar(500) NOT NULL,
+  `file_name` varchar(255) NOT NULL,
+  `original_metadata` json DEFAULT NULL,
+  `content_hash` varchar(64) NOT NULL DEFAULT '',
+  `perceptual_hash` bigint unsigned DEFAULT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_artwork_page` (`artwork_id`,`page`),
+  KEY `idx_artwork_pages_blob_id` (`blob_id`),
+  CONSTRAINT `fk_artworks_pages` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_blobs_artwork_pages` FOREIGN KEY (`blob_id`) REFERENCES `blobs` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 文件修订记录被替换的是哪一页
+ALTER TABLE `artwork_revisions`
+  ADD COLUMN `page` int NOT NULL DEFAULT '1' AFTER `revision`;
@@ -0,0 +1,10 @@
+-- 为作品增加"草稿"状态：草稿不进入审核队列，也不计入上传数量限制，由作者提交后才进入审核
+-- 草稿提交时写入一条从 draft 到 pending 的审核记录
+
+USE art_collection;
+
+ALTER TABLE `artworks`
+  MODIFY COLUMN `review_status` enum('draft','pending','approved','rejected') NOT NULL DEFAULT 'pending';
+
+ALTER TABLE `artwork_reviews`
+  MODIFY COLUMN `previous_status` enum('draft','pending','approved','rejected') NOT NULL;
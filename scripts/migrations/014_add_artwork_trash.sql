@@ -0,0 +1,10 @@
+-- 作品软删除（回收站）：删除的作品先移入回收站，作者和管理员可在保留期内恢复
+-- 超过保留期（upload.trash_retention_days）后由服务端定时彻底删除记录和文件
+
+USE art_collection;
+
+ALTER TABLE `artworks`
+  ADD COLUMN `is_deleted` tinyint(1) NOT NULL DEFAULT '0' AFTER `reject_reason_codes`,
+  ADD COLUMN `deleted_at` datetime(3) DEFAULT NULL AFTER `is_deleted`,
+  ADD KEY `idx_artworks_is_deleted` (`is_deleted`),
+  ADD KEY `idx_artworks_deleted_at` (`deleted_at`);
@@ -0,0 +1,27 @@
+-- 公众投票：活动增加投票开关、投票时间窗口和每人票数上限，新增投票表
+-- 投票实时计数保存在 Redis 中，artworks.vote_count 由服务端定时从 votes 表汇总
+
+USE art_collection;
+
+ALTER TABLE `activities`
+  ADD COLUMN `voting_enabled` tinyint(1) NOT NULL DEFAULT '0' AFTER `gallery_opens_at`,
+  ADD COLUMN `voting_opens_at` datetime(3) DEFAULT NULL AFTER `voting_enabled`,
+  ADD COLUMN `voting_closes_at` datetime(3) DEFAULT NULL AFTER `voting_opens_at`,
+  ADD COLUMN `max_votes_per_user` bigint NOT NULL DEFAULT '0' AFTER `voting_closes_at`;
+
+ALTER TABLE `artworks`
+  ADD COLUMN `vote_count` bigint NOT NULL DEFAULT '0' AFTER `page_count`;
+
+CREATE TABLE IF NOT EXISTS `votes` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `activity_id` bigint unsigned NOT NULL,
+  `artwork_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_vote_artwork_user` (`artwork_id`,`user_id`),
+  KEY `idx_votes_activity_user` (`activity_id`,`user_id`),
+  CONSTRAINT `fk_votes_activity` FOREIGN KEY (`activity_id`) REFERENCES `activities` (`id`),
+  CONSTRAINT `fk_votes_artwork` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_votes_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
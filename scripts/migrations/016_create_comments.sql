@@ -0,0 +1,27 @@
+-- 作品评论：支持回复形成的讨论串，管理员可隐藏或删除评论
+-- root_id 指向讨论串的第一条评论；删除评论时其下的回复一并删除
+
+USE art_collection;
+
+CREATE TABLE IF NOT EXISTS `comments` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `artwork_id` bigint unsigned NOT NULL,
+  `root_id` bigint unsigned DEFAULT NULL,
+  `parent_id` bigint unsigned DEFAULT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `content` text NOT NULL,
+  `is_hidden` tinyint(1) NOT NULL DEFAULT '0',
+  `hidden_by` bigint unsigned DEFAULT NULL,
+  `hidden_at` datetime(3) DEFAULT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_comments_artwork_root` (`artwork_id`,`root_id`),
+  KEY `idx_comments_parent_id` (`parent_id`),
+  KEY `idx_comments_user_id` (`user_id`),
+  KEY `idx_comments_is_hidden` (`is_hidden`),
+  KEY `idx_comments_created_at` (`created_at`),
+  CONSTRAINT `fk_comments_artwork` FOREIGN KEY (`artwork_id`) REFERENCES `artworks` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_comments_parent` FOREIGN KEY (`parent_id`) REFERENCES `comments` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_comments_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;